@@ -0,0 +1,192 @@
+package tello
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAckTimeout is how long to wait for the first acknowledgement.
+	DefaultAckTimeout = 250 * time.Millisecond
+
+	// DefaultRetries is how many times a command is resent before giving up.
+	DefaultRetries = 3
+)
+
+// recentAcks is how many acknowledged commands are remembered to spot
+// duplicated acknowledgements.
+const recentAcks = 16
+
+// ackResult is what an acknowledgement from the drone turned out to be.
+type ackResult byte
+
+const (
+	// ackResolved is an acknowledgement for a command that was waiting.
+	ackResolved ackResult = iota
+
+	// ackDuplicate is a second acknowledgement for a command that was
+	// already acknowledged.
+	ackDuplicate
+
+	// ackStale is an acknowledgement for a command that was sent earlier
+	// but is no longer waiting, such as one that timed out.
+	ackStale
+
+	// ackUnknown is an acknowledgement with a sequence number that was
+	// never sent.
+	ackUnknown
+)
+
+// pendingAck is a command that is waiting for the drone to acknowledge it.
+type pendingAck struct {
+	id   uint16
+	seq  uint16
+	done chan struct{}
+}
+
+// ackKey identifies a command by its message ID and sequence number.
+type ackKey struct {
+	id, seq uint16
+}
+
+// ackTracker allocates sequence numbers and matches acknowledgements from
+// the drone with the commands waiting for them.
+type ackTracker struct {
+	mu      sync.Mutex
+	seq     uint16
+	pending []*pendingAck
+
+	// recent is a ring of the last commands that were acknowledged.
+	recent [recentAcks]ackKey
+	next   int
+}
+
+// nextSeq returns the next sequence number. Sequence numbers wrap around
+// and skip 0, so that they never match packets the drone sends unsequenced.
+// The caller must hold mu.
+func (a *ackTracker) nextSeq() uint16 {
+	a.seq++
+	if a.seq == 0 {
+		a.seq = 1
+	}
+
+	return a.seq
+}
+
+// sequence returns the sequence number for a command that is not
+// acknowledged.
+func (a *ackTracker) sequence() uint16 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.nextSeq()
+}
+
+// add allocates a sequence number for a command and registers it as
+// waiting for an acknowledgement.
+func (a *ackTracker) add(id uint16) *pendingAck {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	p := &pendingAck{id: id, seq: a.nextSeq(), done: make(chan struct{})}
+	a.pending = append(a.pending, p)
+
+	return p
+}
+
+// remove stops tracking a command.
+func (a *ackTracker) remove(p *pendingAck) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i, q := range a.pending {
+		if q == p {
+			a.pending = append(a.pending[:i], a.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// resolve marks the command with the same ID and sequence as acknowledged.
+// If no command was waiting for it, it tells whether the acknowledgement
+// is a duplicate, stale or for a sequence number that was never sent.
+func (a *ackTracker) resolve(id, seq uint16) ackResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := ackKey{id, seq}
+	for i, p := range a.pending {
+		if p.id == id && p.seq == seq {
+			close(p.done)
+			a.pending = append(a.pending[:i], a.pending[i+1:]...)
+			a.recent[a.next] = key
+			a.next = (a.next + 1) % recentAcks
+			return ackResolved
+		}
+	}
+
+	for _, k := range a.recent {
+		if k == key && seq != 0 {
+			return ackDuplicate
+		}
+	}
+
+	// Compare using serial number arithmetic so that wraparound is handled.
+	if seq != 0 && int16(seq-a.seq) <= 0 {
+		return ackStale
+	}
+
+	return ackUnknown
+}
+
+// SetRetries sets how long to wait for the drone to acknowledge a command,
+// and how many times to resend it. The wait doubles after each resend.
+func (t *Tello) SetRetries(retries int, timeout time.Duration) {
+	t.cmdMutex.Lock()
+	defer t.cmdMutex.Unlock()
+
+	t.retries = retries
+	t.ackTimeout = timeout
+}
+
+// sendAndWait sends a command that the drone acknowledges, resending it
+// with backoff until an acknowledgement with the same ID and sequence
+// arrives, or returns ErrTimeout once the retries are used up.
+func (t *Tello) sendAndWait(id uint16, payload []byte) error {
+	t.cmdMutex.Lock()
+	retries, timeout := t.retries, t.ackTimeout
+	done := t.done
+	t.cmdMutex.Unlock()
+
+	p := t.acks.add(id)
+	defer t.acks.remove(p)
+
+	pkt, err := newPacket(id, p.seq, payload)
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for attempt := 0; ; attempt++ {
+		if err := t.sendPacket(pkt); err != nil {
+			return err
+		}
+
+		select {
+		case <-p.done:
+			return nil
+		case <-done:
+			return ErrNotConnected
+		case <-timer.C:
+		}
+
+		if attempt >= retries {
+			return ErrTimeout
+		}
+
+		timeout *= 2
+		timer.Reset(timeout)
+	}
+}
@@ -0,0 +1,74 @@
+package tello
+
+import "testing"
+
+func TestNextSeqWraps(t *testing.T) {
+	a := &ackTracker{seq: 0xfffe}
+
+	for _, want := range []uint16{0xffff, 1, 2} {
+		if got := a.sequence(); got != want {
+			t.Fatalf("got sequence %#x, want %#x", got, want)
+		}
+	}
+}
+
+func TestResolveAcrossWrap(t *testing.T) {
+	a := &ackTracker{seq: 0xfffd}
+
+	land := a.add(landCommand)
+	takeoff := a.add(takeoffCommand)
+	flip := a.add(flipCommand)
+
+	if land.seq != 0xfffe || takeoff.seq != 0xffff || flip.seq != 1 {
+		t.Fatalf("got sequences %#x %#x %#x", land.seq, takeoff.seq, flip.seq)
+	}
+
+	// The takeoff gave up waiting before its acknowledgement arrived.
+	a.remove(takeoff)
+
+	tests := []struct {
+		name string
+		id   uint16
+		seq  uint16
+		want ackResult
+	}{
+		{"land", landCommand, 0xfffe, ackResolved},
+		{"land again", landCommand, 0xfffe, ackDuplicate},
+		{"takeoff after timeout", takeoffCommand, 0xffff, ackStale},
+		{"flip", flipCommand, 1, ackResolved},
+		{"flip again", flipCommand, 1, ackDuplicate},
+		{"before wrap", flipCommand, 0xfff0, ackStale},
+		{"never sent", flipCommand, 2, ackUnknown},
+		{"far ahead", flipCommand, 0x7000, ackUnknown},
+		{"zero", landCommand, 0, ackUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := a.resolve(tt.id, tt.seq); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	select {
+	case <-land.done:
+	default:
+		t.Error("land was not marked as acknowledged")
+	}
+}
+
+func TestResolveForgetsOldAcks(t *testing.T) {
+	a := &ackTracker{}
+
+	first := a.add(landCommand)
+	a.resolve(landCommand, first.seq)
+
+	for i := 0; i < recentAcks; i++ {
+		p := a.add(flipCommand)
+		a.resolve(flipCommand, p.seq)
+	}
+
+	// Once it has left the recent acks, a repeat is only known to be old.
+	if got := a.resolve(landCommand, first.seq); got != ackStale {
+		t.Errorf("got %d, want %d", got, ackStale)
+	}
+}
@@ -0,0 +1,93 @@
+package tello
+
+// Result is the outcome of a command that was sent without waiting for the
+// drone to acknowledge it.
+type Result struct {
+	done chan struct{}
+	err  error
+}
+
+// Done returns a channel that is closed once the command has been
+// acknowledged or has timed out.
+func (r *Result) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err returns nil if the command was acknowledged, or the reason it failed.
+// It returns nil until Done is closed.
+func (r *Result) Err() error {
+	select {
+	case <-r.done:
+		return r.err
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the command completes and returns its error.
+func (r *Result) Wait() error {
+	<-r.done
+	return r.err
+}
+
+// failedResult returns a Result that has already failed with err.
+func failedResult(err error) *Result {
+	r := &Result{done: make(chan struct{}), err: err}
+	close(r.done)
+
+	return r
+}
+
+// sendAsync runs sendAndWait in the background. If acked is not nil, it is
+// called once the command has been acknowledged.
+func (t *Tello) sendAsync(id uint16, payload []byte, acked func()) *Result {
+	r := &Result{done: make(chan struct{})}
+
+	go func() {
+		r.err = t.sendAndWait(id, payload)
+		if r.err == nil && acked != nil {
+			acked()
+		}
+		close(r.done)
+	}()
+
+	return r
+}
+
+// TakeOffAsync tells the Tello to takeoff without waiting for it to acknowledge.
+func (t *Tello) TakeOffAsync() *Result {
+	if err := t.checkFlying(false, true); err != nil {
+		return failedResult(err)
+	}
+
+	return t.sendAsync(takeoffCommand, nil, nil)
+}
+
+// LandAsync tells the Tello to land without waiting for it to acknowledge.
+func (t *Tello) LandAsync() *Result {
+	return t.sendAsync(landCommand, []byte{0x00}, nil)
+}
+
+// ThrowTakeOffAsync starts Throw & Go without waiting for the drone to acknowledge.
+func (t *Tello) ThrowTakeOffAsync() *Result {
+	if err := t.checkFlying(false, true); err != nil {
+		return failedResult(err)
+	}
+
+	return t.sendAsync(throwtakeoffCommand, nil, t.setThrowMode)
+}
+
+// PalmLandAsync tells drone to land on the palm of your hand without waiting
+// for it to acknowledge.
+func (t *Tello) PalmLandAsync() *Result {
+	return t.sendAsync(palmLandCommand, []byte{0x00}, t.setPalmLanding)
+}
+
+// FlipAsync tells drone to flip without waiting for it to acknowledge.
+func (t *Tello) FlipAsync(direction FlipType) *Result {
+	if err := t.checkFlying(true, true); err != nil {
+		return failedResult(err)
+	}
+
+	return t.sendAsync(flipCommand, []byte{byte(direction)}, nil)
+}
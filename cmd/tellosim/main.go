@@ -0,0 +1,42 @@
+// Command tellosim runs a simulated Tello drone on the local machine.
+//
+// Point a client at it with a tello.Config such as:
+//
+//	tello.NewWithConfig(tello.Config{DroneAddr: "127.0.0.1"})
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/hybridgroup/tinygo-tello/tellosim"
+)
+
+func main() {
+	addr := flag.String("addr", ":8889", "UDP address to listen on for commands")
+	verbose := flag.Bool("v", false, "log every message received")
+	flag.Parse()
+
+	cfg := tellosim.Config{MaxSticks: 100}
+	if *verbose {
+		cfg.Log = func(msg string) {
+			log.Println(msg)
+		}
+	}
+
+	drone := tellosim.New(cfg)
+
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		drone.Close()
+	}()
+
+	log.Println("simulated drone listening on", *addr)
+	if err := drone.ListenAndServe(*addr); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,24 +1,55 @@
 package tello
 
 const (
-	messageStart   = 0x00cc // 204
-	wifiMessage    = 0x001a // 26
-	videoRateQuery = 0x0028 // 40
-	lightMessage   = 0x0035 // 53
-	flightMessage  = 0x0056 // 86
-	logMessage     = 0x1050 // 4176
+	messageStart = 0x00cc // 204
 
+	ssidMessage             = 0x0011 // 17
+	ssidCommand             = 0x0012 // 18
+	ssidPasswordMessage     = 0x0013 // 19
+	ssidPasswordCommand     = 0x0014 // 20
+	wifiRegionMessage       = 0x0015 // 21
+	wifiRegionCommand       = 0x0016 // 22
+	wifiMessage             = 0x001a // 26
 	videoEncoderRateCommand = 0x0020 // 32
+	videoDynAdjRateCommand  = 0x0021 // 33
+	eisCommand              = 0x0024 // 36
 	videoStartCommand       = 0x0025 // 37
+	videoRateQuery          = 0x0028 // 40
+	takePictureCommand      = 0x0030 // 48
+	videoModeCommand        = 0x0031 // 49
+	videoRecordCommand      = 0x0032 // 50
 	exposureCommand         = 0x0034 // 52
+	lightMessage            = 0x0035 // 53
+	jpegQualityMessage      = 0x0037 // 55
+	error1Message           = 0x0043 // 67
+	error2Message           = 0x0044 // 68
+	versionMessage          = 0x0045 // 69
 	timeCommand             = 0x0046 // 70
+	activationTimeMessage   = 0x0047 // 71
+	loaderVersionMessage    = 0x0049 // 73
 	stickCommand            = 0x0050 // 80
 	takeoffCommand          = 0x0054 // 84
 	landCommand             = 0x0055 // 85
+	flightMessage           = 0x0056 // 86
+	altLimitCommand         = 0x0058 // 88
 	flipCommand             = 0x005c // 92
 	throwtakeoffCommand     = 0x005d // 93
 	palmLandCommand         = 0x005e // 94
+	fileSizeMessage         = 0x0062 // 98
+	fileDataMessage         = 0x0063 // 99
+	fileCompleteMessage     = 0x0064 // 100
+	smartVideoCommand       = 0x0080 // 128
+	smartVideoStatusMessage = 0x0081 // 129
+	logMessage              = 0x1050 // 4176
+	logDataMessage          = 0x1051 // 4177
+	logConfigMessage        = 0x1052 // 4178
 	bounceCommand           = 0x1053 // 4179
+	calibrateCommand        = 0x1054 // 4180
+	lowBatThresholdCommand  = 0x1055 // 4181
+	altLimitMessage         = 0x1056 // 4182
+	lowBatThresholdMessage  = 0x1057 // 4183
+	attLimitCommand         = 0x1058 // 4184
+	attLimitMessage         = 0x1059 // 4185
 )
 
 // FlipType is used for the various flips supported by the Tello.
@@ -0,0 +1,174 @@
+package tello
+
+import "time"
+
+const (
+	// DefaultDroneAddr is the address of the drone on its own WiFi network.
+	DefaultDroneAddr = "192.168.10.1"
+
+	// DefaultCommandPort is the port the drone listens on for commands.
+	DefaultCommandPort = "8889"
+
+	// DefaultResponsePort is the local port that receives responses.
+	DefaultResponsePort = "8888"
+
+	// DefaultVideoPort is the local port that the drone sends video to.
+	DefaultVideoPort = "11111"
+
+	// DefaultStickInterval is how often the stick positions are sent.
+	DefaultStickInterval = 100 * time.Millisecond
+
+	// DefaultLinkTimeout is how long without hearing from the drone before
+	// the connection is considered lost.
+	DefaultLinkTimeout = 2 * time.Second
+
+	// DefaultConnectInterval is how often StartContext resends the
+	// connection request while waiting for the drone to answer.
+	DefaultConnectInterval = 500 * time.Millisecond
+)
+
+// Failsafe is what the client does when the connection to the drone is lost.
+type Failsafe int
+
+const (
+	// FailsafeNone leaves the sticks as they are.
+	FailsafeNone Failsafe = iota
+
+	// FailsafeHover centers the sticks so that the drone hovers.
+	FailsafeHover
+
+	// FailsafeLand centers the sticks and tells the drone to land.
+	FailsafeLand
+)
+
+// Config is the configuration for a Tello client. Any zero field uses
+// its default.
+type Config struct {
+	// DroneAddr is the address of the drone. Default is DefaultDroneAddr.
+	DroneAddr string
+
+	// CommandPort is the port the drone listens on for commands.
+	// Default is DefaultCommandPort.
+	CommandPort string
+
+	// LocalAddr is the local address to bind to. Default is all addresses.
+	LocalAddr string
+
+	// ResponsePort is the local port that receives responses.
+	// Default is DefaultResponsePort.
+	ResponsePort string
+
+	// VideoPort is the local port that the drone sends video to.
+	// Default is DefaultVideoPort.
+	VideoPort string
+
+	// StickInterval is how often the stick positions are sent. It can be
+	// changed later with SetStickInterval. Default is DefaultStickInterval.
+	StickInterval time.Duration
+
+	// StickTimeout centers the sticks if no movement method or SetSticks
+	// is called within it, and publishes a StickTimeoutEvent. Default is
+	// to never time out.
+	StickTimeout time.Duration
+
+	// ConnectInterval is how often StartContext resends the connection
+	// request, and how often the link is checked. Default is
+	// DefaultConnectInterval.
+	ConnectInterval time.Duration
+
+	// LinkTimeout is how long without hearing from the drone before the
+	// connection is considered lost. The connection request is then resent
+	// until the drone answers again. Default is DefaultLinkTimeout. Use a
+	// negative value to never time out.
+	LinkTimeout time.Duration
+
+	// Failsafe is what to do when the connection is lost. Default is
+	// FailsafeNone.
+	Failsafe Failsafe
+
+	// AckTimeout is how long to wait for the first acknowledgement of a
+	// command. Default is DefaultAckTimeout.
+	AckTimeout time.Duration
+
+	// Retries is how many times a command is resent before giving up.
+	// Default is DefaultRetries. Use a negative value to never resend.
+	Retries int
+
+	// Dialer opens the transports used to talk to the drone.
+	// Default is UDPDialer.
+	Dialer Dialer
+
+	// Logger receives diagnostic messages. Default is to discard them.
+	Logger Logger
+
+	// ErrorInterval is how often the same background error is reported to
+	// Errors and the Logger. Default is DefaultErrorInterval.
+	ErrorInterval time.Duration
+}
+
+// NewWithConfig returns a new Tello client using cfg.
+func NewWithConfig(cfg Config) *Tello {
+	if cfg.DroneAddr == "" {
+		cfg.DroneAddr = DefaultDroneAddr
+	}
+	if cfg.CommandPort == "" {
+		cfg.CommandPort = DefaultCommandPort
+	}
+	if cfg.ResponsePort == "" {
+		cfg.ResponsePort = DefaultResponsePort
+	}
+	if cfg.VideoPort == "" {
+		cfg.VideoPort = DefaultVideoPort
+	}
+	if cfg.StickInterval <= 0 {
+		cfg.StickInterval = DefaultStickInterval
+	}
+	if cfg.ConnectInterval <= 0 {
+		cfg.ConnectInterval = DefaultConnectInterval
+	}
+	if cfg.LinkTimeout == 0 {
+		cfg.LinkTimeout = DefaultLinkTimeout
+	}
+	if cfg.AckTimeout == 0 {
+		cfg.AckTimeout = DefaultAckTimeout
+	}
+	if cfg.Dialer == nil {
+		cfg.Dialer = UDPDialer{}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = nopLogger{}
+	}
+	if cfg.ErrorInterval == 0 {
+		cfg.ErrorInterval = DefaultErrorInterval
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = DefaultRetries
+	} else if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+
+	return &Tello{
+		reqAddr:   cfg.DroneAddr,
+		reqPort:   cfg.CommandPort,
+		localAddr: cfg.LocalAddr,
+		respPort:  cfg.ResponsePort,
+		videoPort: cfg.VideoPort,
+		dialer:    cfg.Dialer,
+
+		stickInterval:   cfg.StickInterval,
+		stickTimeout:    cfg.StickTimeout,
+		connectInterval: cfg.ConnectInterval,
+		linkTimeout:     cfg.LinkTimeout,
+		failsafe:        cfg.Failsafe,
+
+		retries:    cfg.Retries,
+		ackTimeout: cfg.AckTimeout,
+
+		logger: cfg.Logger,
+		errors: errorReporter{
+			ch:       make(chan error, errorQueueLen),
+			interval: cfg.ErrorInterval,
+			last:     make(map[string]reported),
+		},
+	}
+}
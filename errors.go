@@ -0,0 +1,65 @@
+package tello
+
+import "errors"
+
+var (
+	// ErrNotConnected is returned when sending to a drone before Start,
+	// or after Stop.
+	ErrNotConnected = errors.New("tello: not connected")
+
+	// ErrAlreadyStarted is returned by Start if the client is already running.
+	ErrAlreadyStarted = errors.New("tello: already started")
+
+	// ErrNotReachable is returned by StartContext if the drone does not
+	// answer the connection request. The error also matches the reason the
+	// context ended.
+	ErrNotReachable = errors.New("tello: drone not reachable")
+
+	// ErrTimeout is returned when the drone does not acknowledge a command,
+	// or does not reach the flight state that is being waited for. When
+	// waiting ends with a context, the error also matches the reason the
+	// context ended.
+	ErrTimeout = errors.New("tello: timed out")
+
+	// ErrNotFlying is returned by Flip when flight data says the drone is
+	// on the ground. Landing is never refused because of flight data.
+	ErrNotFlying = errors.New("tello: not flying")
+
+	// ErrAlreadyFlying is returned by TakeOff and ThrowTakeOff when flight
+	// data says the drone is already in the air.
+	ErrAlreadyFlying = errors.New("tello: already flying")
+
+	// ErrLowBattery is returned by TakeOff, ThrowTakeOff and Flip when
+	// flight data says the battery is low.
+	ErrLowBattery = errors.New("tello: battery low")
+
+	// ErrOutOfRange is returned by the movement methods when given a value
+	// outside of the range they accept. The value is clamped to the range
+	// and still used.
+	ErrOutOfRange = errors.New("tello: value out of range")
+
+	// ErrQueueFull is returned when too many commands are waiting to be
+	// sent to the drone.
+	ErrQueueFull = errors.New("tello: send queue full")
+)
+
+// contextError is returned when a wait ends with its context, so that
+// errors.Is matches both err and the context's error.
+type contextError struct {
+	err   error
+	cause error
+}
+
+func (e *contextError) Error() string {
+	return e.err.Error() + ": " + e.cause.Error()
+}
+
+// Is reports whether target is the client's error.
+func (e *contextError) Is(target error) bool {
+	return target == e.err
+}
+
+// Unwrap returns the context's error.
+func (e *contextError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,29 @@
+package tello
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartContextError(t *testing.T) {
+	// Nothing answers on the far end of the pipe.
+	drone := NewWithConfig(Config{Dialer: &PipeDialer{}, ConnectInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := drone.StartContext(ctx)
+	if !errors.Is(err, ErrNotReachable) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want ErrNotReachable and context.DeadlineExceeded", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	err = drone.StartContext(ctx)
+	if !errors.Is(err, ErrNotReachable) || !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want ErrNotReachable and context.Canceled", err)
+	}
+}
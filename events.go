@@ -0,0 +1,166 @@
+package tello
+
+import "sync"
+
+// EventType is the kind of an Event.
+type EventType int
+
+const (
+	// StickTimeoutEvent is published when the sticks were not refreshed
+	// within the stick timeout and have been centered.
+	StickTimeoutEvent EventType = iota + 1
+
+	// ConnectionLostEvent is published when nothing has been heard from the
+	// drone within the link timeout.
+	ConnectionLostEvent
+
+	// ConnectionRestoredEvent is published when the drone is heard from
+	// again after the connection was lost.
+	ConnectionRestoredEvent
+
+	// FlightStateEvent is published when the flight state changes. Data is
+	// the new FlightState.
+	FlightStateEvent
+
+	// ConnectedEvent is published when the drone answers the connection
+	// request after Start.
+	ConnectedEvent
+
+	// DisconnectedEvent is published by Stop.
+	DisconnectedEvent
+
+	// FlightDataEvent is published for each flight data message. Data is
+	// a FlightData.
+	FlightDataEvent
+
+	// WiFiEvent is published for each WiFi message. Data is a WiFiData.
+	WiFiEvent
+
+	// LightEvent is published for each light message. Data is the light
+	// strength as a byte.
+	LightEvent
+
+	// LogDataEvent is published for each log data message. Data is the
+	// payload as a []byte.
+	LogDataEvent
+
+	// TakeOffEvent is published when the drone acknowledges a takeoff or
+	// throw takeoff.
+	TakeOffEvent
+
+	// LandingEvent is published when the drone acknowledges a land or palm
+	// land.
+	LandingEvent
+
+	// LowBatteryEvent is published when the flight data first reports the
+	// battery as low. Data is the battery percentage as an int8.
+	LowBatteryEvent
+
+	// VideoFrameEvent is published for each video frame received. Data is
+	// the H.264 data of the frame as a []byte.
+	VideoFrameEvent
+)
+
+// Event is something that happened on the drone or in the client.
+type Event struct {
+	Type EventType
+
+	// Data holds information about the event. Its type depends on Type.
+	Data interface{}
+}
+
+// WiFiData is the strength of the drone's WiFi signal.
+type WiFiData struct {
+	Strength int8
+	Disturb  int8
+}
+
+// eventHub fans events out to subscribers without blocking.
+type eventHub struct {
+	mu   sync.Mutex
+	subs []subscriber
+}
+
+// subscriber is a channel and the event types it wants.
+type subscriber struct {
+	ch    chan Event
+	types []EventType
+}
+
+// wants returns true if the subscriber wants events of type typ.
+func (s *subscriber) wants(typ EventType) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+
+	for _, t := range s.types {
+		if t == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subscribe returns a channel that receives events of the given types, or
+// of every type if none are given. It holds up to size events; when it is
+// full, new events are dropped for that subscriber rather than holding up
+// the client.
+func (t *Tello) Subscribe(size int, types ...EventType) <-chan Event {
+	ch := make(chan Event, size)
+
+	t.events.mu.Lock()
+	t.events.subs = append(t.events.subs, subscriber{ch: ch, types: types})
+	t.events.mu.Unlock()
+
+	return ch
+}
+
+// Unsubscribe stops sending events to ch and closes it.
+func (t *Tello) Unsubscribe(ch <-chan Event) {
+	t.events.mu.Lock()
+	defer t.events.mu.Unlock()
+
+	for i, sub := range t.events.subs {
+		if sub.ch == ch {
+			t.events.subs = append(t.events.subs[:i], t.events.subs[i+1:]...)
+			close(sub.ch)
+			return
+		}
+	}
+}
+
+// subscribed returns true if anyone wants events of type typ, so that the
+// data for an event is only built when it will be used.
+func (t *Tello) subscribed(typ EventType) bool {
+	t.events.mu.Lock()
+	defer t.events.mu.Unlock()
+
+	for i := range t.events.subs {
+		if t.events.subs[i].wants(typ) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publish sends an event to every subscriber that wants it and has room
+// for it.
+func (t *Tello) publish(typ EventType, data interface{}) {
+	t.events.mu.Lock()
+	defer t.events.mu.Unlock()
+
+	e := Event{Type: typ, Data: data}
+	for i := range t.events.subs {
+		sub := &t.events.subs[i]
+		if !sub.wants(typ) {
+			continue
+		}
+
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
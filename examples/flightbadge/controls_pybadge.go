@@ -31,38 +31,28 @@ func readControls() {
 	buttons.Configure()
 
 	for {
+		checkPending()
 		buttons.ReadInput()
 
 		// takeoff
 		if buttons.Pins[shifter.BUTTON_START].Get() {
-			if !takeoff {
+			if pending == nil && !drone.FlightState().InAir() {
 				terminalOutput("takeoff")
-				err := drone.TakeOff()
-				if err != nil {
-					terminalOutput(err.Error())
-				}
-				takeoff = true
+				pending = drone.TakeOffAsync()
 			}
 		}
 
 		// land
 		if buttons.Pins[shifter.BUTTON_B].Get() {
 			terminalOutput("landing")
-			err := drone.Land()
-			if err != nil {
-				terminalOutput(err.Error())
-			}
-			takeoff = false
+			pending = drone.LandAsync()
 		}
 
 		// front flip
 		if buttons.Pins[shifter.BUTTON_SELECT].Get() {
 			if !flip {
 				terminalOutput("flip")
-				err := drone.Flip(tello.FlipFront)
-				if err != nil {
-					terminalOutput(err.Error())
-				}
+				pending = drone.FlipAsync(tello.FlipFront)
 				flip = true
 			}
 		}
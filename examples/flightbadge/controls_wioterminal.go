@@ -37,37 +37,27 @@ func readControls() {
 	machine.BUTTON_3.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
 
 	for {
+		checkPending()
+
 		// takeoff
 		if !machine.BUTTON_2.Get() {
-			if !takeoff {
+			if pending == nil && !drone.FlightState().InAir() {
 				terminalOutput("takeoff")
-				err := drone.ThrowTakeOff()
-				if err != nil {
-					terminalOutput(err.Error())
-				}
-				takeoff = true
+				pending = drone.ThrowTakeOffAsync()
 			}
 		}
 
 		// land
 		if !machine.BUTTON_1.Get() {
 			terminalOutput("landing")
-			err := drone.Land()
-			if err != nil {
-				terminalOutput(err.Error())
-			}
-			takeoff = false
+			pending = drone.LandAsync()
 		}
 
 		// hand landing
 		if !machine.WIO_5S_PRESS.Get() {
 			if !handlanding {
 				terminalOutput("hand landing")
-				err := drone.PalmLand()
-				if err != nil {
-					terminalOutput(err.Error())
-				}
-				takeoff = false
+				pending = drone.PalmLandAsync()
 				handlanding = true
 			}
 		}
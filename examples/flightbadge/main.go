@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"tinygo.org/x/drivers/netlink"
@@ -20,8 +21,10 @@ var (
 	drone *tello.Tello
 
 	droneconnected bool
-	takeoff        bool
 	direction      int
+
+	// pending is the last command sent to the drone that has not completed.
+	pending *tello.Result
 )
 
 const speed = 30
@@ -65,7 +68,11 @@ func main() {
 
 func connectDrone() {
 	terminalOutput("Starting drone...")
-	if err := drone.Start(); err != nil {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := drone.StartContext(ctx); err != nil {
 		failMessage(err.Error())
 	}
 
@@ -89,46 +96,54 @@ func controlDrone() {
 			continue
 		}
 
+		var rx, ry, lx, ly int
 		switch direction {
 		case directionForward:
-			drone.Forward(speed)
+			ry = speed
 		case directionBackward:
-			drone.Backward(speed)
-		default:
-			drone.Forward(0)
-		}
-
-		switch direction {
+			ry = -speed
 		case directionLeft:
-			drone.Left(speed)
+			rx = -speed
 		case directionRight:
-			drone.Right(speed)
-		default:
-			drone.Right(0)
-		}
-
-		switch direction {
+			rx = speed
 		case directionUp:
-			drone.Up(speed)
+			ly = speed
 		case directionDown:
-			drone.Down(speed)
-		default:
-			drone.Up(0)
-		}
-
-		switch direction {
+			ly = -speed
 		case directionTurnLeft:
-			drone.CounterClockwise(speed)
+			lx = -speed
 		case directionTurnRight:
-			drone.Clockwise(speed)
+			lx = speed
+		}
+
+		drone.SetSticks(rx, ry, lx, ly)
+
+		select {
+		case err := <-drone.Errors():
+			terminalOutput(err.Error())
 		default:
-			drone.Clockwise(0)
 		}
 
 		time.Sleep(100 * time.Millisecond)
 	}
 }
 
+// checkPending reports the outcome of the last command once it completes.
+func checkPending() {
+	if pending == nil {
+		return
+	}
+
+	select {
+	case <-pending.Done():
+		if err := pending.Err(); err != nil {
+			terminalOutput(err.Error())
+		}
+		pending = nil
+	default:
+	}
+}
+
 func failMessage(msg string) {
 	for {
 		terminalOutput(msg)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"time"
 
@@ -29,25 +30,39 @@ func main() {
 
 	drone := tello.New("8888")
 	println("Starting drone")
-	if err := drone.Start(); err != nil {
-		for {
-			println(err.Error())
-			time.Sleep(1 * time.Second)
-		}
-	}
 
-	time.Sleep(5 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := drone.StartContext(ctx); err != nil {
+		failure(err)
+	}
 
 	println("Taking off")
-	if err := drone.TakeOff(); err != nil {
-		for {
-			println(err.Error())
-			time.Sleep(1 * time.Second)
-		}
+	takeoff, cancelTakeoff := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelTakeoff()
+
+	if err := drone.TakeOffAndWait(takeoff); err != nil {
+		failure(err)
 	}
 
 	time.Sleep(5 * time.Second)
 
 	println("Landing")
-	drone.Land()
+	landing, cancelLanding := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelLanding()
+
+	if err := drone.LandAndWait(landing); err != nil {
+		failure(err)
+	}
+
+	println("Landed")
+}
+
+// failure prints err forever, so that it can be read on the console.
+func failure(err error) {
+	for {
+		println(err.Error())
+		time.Sleep(1 * time.Second)
+	}
 }
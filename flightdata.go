@@ -0,0 +1,153 @@
+package tello
+
+import "encoding/binary"
+
+// flightDataLen is the size of the flight data payload.
+const flightDataLen = 24
+
+// FlightData is the status report that the drone sends several times a second.
+type FlightData struct {
+	// Height is in decimeters.
+	Height int16
+
+	// NorthSpeed, EastSpeed and VerticalSpeed are in decimeters per second.
+	NorthSpeed    int16
+	EastSpeed     int16
+	VerticalSpeed int16
+
+	// FlyTime is how long the drone has been flying, in tenths of a second.
+	FlyTime int16
+
+	ImuState        bool
+	PressureState   bool
+	DownVisualState bool
+	PowerState      bool
+	BatteryState    bool
+	GravityState    bool
+	WindState       bool
+
+	ImuCalibrationState int8
+	BatteryPercentage   int8
+	DroneFlyTimeLeft    int16
+	DroneBatteryLeft    int16
+
+	Flying          bool
+	OnGround        bool
+	EmOpen          bool
+	DroneHover      bool
+	OutageRecording bool
+	BatteryLow      bool
+	BatteryLower    bool
+	FactoryMode     bool
+
+	FlyMode                  byte
+	ThrowFlyTimer            byte
+	CameraState              byte
+	ElectricalMachineryState byte
+
+	FrontIn  bool
+	FrontOut bool
+	FrontLSC bool
+
+	TemperatureHigh bool
+}
+
+// ParseFlightData decodes the payload of a flight data message.
+func ParseFlightData(b []byte) (fd FlightData, err error) {
+	if len(b) < flightDataLen {
+		return fd, ErrPacketLength
+	}
+
+	fd.Height = int16(binary.LittleEndian.Uint16(b[0:]))
+	fd.NorthSpeed = int16(binary.LittleEndian.Uint16(b[2:]))
+	fd.EastSpeed = int16(binary.LittleEndian.Uint16(b[4:]))
+	fd.VerticalSpeed = int16(binary.LittleEndian.Uint16(b[6:]))
+	fd.FlyTime = int16(binary.LittleEndian.Uint16(b[8:]))
+
+	fd.ImuState = b[10]&0x01 != 0
+	fd.PressureState = b[10]&0x02 != 0
+	fd.DownVisualState = b[10]&0x04 != 0
+	fd.PowerState = b[10]&0x08 != 0
+	fd.BatteryState = b[10]&0x10 != 0
+	fd.GravityState = b[10]&0x20 != 0
+	fd.WindState = b[10]&0x80 != 0
+
+	fd.ImuCalibrationState = int8(b[11])
+	fd.BatteryPercentage = int8(b[12])
+	fd.DroneFlyTimeLeft = int16(binary.LittleEndian.Uint16(b[13:]))
+	fd.DroneBatteryLeft = int16(binary.LittleEndian.Uint16(b[15:]))
+
+	fd.Flying = b[17]&0x01 != 0
+	fd.OnGround = b[17]&0x02 != 0
+	fd.EmOpen = b[17]&0x04 != 0
+	fd.DroneHover = b[17]&0x08 != 0
+	fd.OutageRecording = b[17]&0x10 != 0
+	fd.BatteryLow = b[17]&0x20 != 0
+	fd.BatteryLower = b[17]&0x40 != 0
+	fd.FactoryMode = b[17]&0x80 != 0
+
+	fd.FlyMode = b[18]
+	fd.ThrowFlyTimer = b[19]
+	fd.CameraState = b[20]
+	fd.ElectricalMachineryState = b[21]
+
+	fd.FrontIn = b[22]&0x01 != 0
+	fd.FrontOut = b[22]&0x02 != 0
+	fd.FrontLSC = b[22]&0x04 != 0
+
+	fd.TemperatureHigh = b[23]&0x01 != 0
+
+	return fd, nil
+}
+
+// FlightData returns the last flight data received from the drone, and
+// false if none has been received since Start.
+func (t *Tello) FlightData() (FlightData, bool) {
+	t.stateMutex.Lock()
+	defer t.stateMutex.Unlock()
+
+	return t.flightData, t.hasFlightData
+}
+
+// setFlightData stores the flight data received from the drone, and
+// publishes a FlightStateEvent if the flight state changed and a
+// LowBatteryEvent when the battery first becomes low.
+func (t *Tello) setFlightData(fd FlightData) {
+	t.stateMutex.Lock()
+	t.hasFlightData = true
+
+	lowBattery := fd.BatteryLow && !t.flightData.BatteryLow
+	t.flightData = fd
+
+	prev := t.flightState
+	t.flightState = t.nextFlightState(fd)
+	changed := t.flightState != prev
+	state := t.flightState
+	t.stateMutex.Unlock()
+
+	if changed {
+		t.publish(FlightStateEvent, state)
+	}
+	if lowBattery {
+		t.publish(LowBatteryEvent, fd.BatteryPercentage)
+	}
+}
+
+// checkFlying returns ErrNotFlying or ErrAlreadyFlying if the last flight
+// data does not match flying. It returns ErrLowBattery if lowBattery is set
+// and the battery is low. Nothing is checked until flight data arrives.
+func (t *Tello) checkFlying(flying, lowBattery bool) error {
+	fd, ok := t.FlightData()
+	switch {
+	case !ok:
+		return nil
+	case flying && !fd.Flying:
+		return ErrNotFlying
+	case !flying && fd.Flying:
+		return ErrAlreadyFlying
+	case lowBattery && fd.BatteryLow:
+		return ErrLowBattery
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package tello
+
+// Fly modes reported in the flight data.
+const (
+	flyModeTakeOff = 11
+	flyModeLanding = 12
+)
+
+// throwModeReports is how many flight data reports on the ground, without
+// the throw timer running, end throw mode.
+const throwModeReports = 10
+
+// FlightState is what the drone is doing, according to its flight data.
+type FlightState int
+
+const (
+	// StateUnknown is before any flight data has been received.
+	StateUnknown FlightState = iota
+
+	// StateOnGround is when the drone is landed.
+	StateOnGround
+
+	// StateTakingOff is when the drone is climbing after a takeoff.
+	StateTakingOff
+
+	// StateFlying is when the drone is in the air.
+	StateFlying
+
+	// StateLanding is when the drone is descending to land.
+	StateLanding
+
+	// StatePalmLanding is when the drone is landing on a hand.
+	StatePalmLanding
+
+	// StateThrowMode is when the drone is waiting to be thrown.
+	StateThrowMode
+)
+
+// String returns the name of the state.
+func (s FlightState) String() string {
+	switch s {
+	case StateOnGround:
+		return "on ground"
+	case StateTakingOff:
+		return "taking off"
+	case StateFlying:
+		return "flying"
+	case StateLanding:
+		return "landing"
+	case StatePalmLanding:
+		return "palm landing"
+	case StateThrowMode:
+		return "throw mode"
+	}
+
+	return "unknown"
+}
+
+// InAir returns true if the drone is off the ground.
+func (s FlightState) InAir() bool {
+	switch s {
+	case StateTakingOff, StateFlying, StateLanding, StatePalmLanding:
+		return true
+	}
+
+	return false
+}
+
+// FlightState returns what the drone is doing, according to the last flight
+// data received.
+func (t *Tello) FlightState() FlightState {
+	t.stateMutex.Lock()
+	defer t.stateMutex.Unlock()
+
+	return t.flightState
+}
+
+// nextFlightState works out the flight state from new flight data. Palm
+// landing and throw mode look like landing and being on the ground in the
+// flight data, so they are told apart by the command the client last sent.
+// The caller must hold stateMutex.
+func (t *Tello) nextFlightState(fd FlightData) FlightState {
+	if t.throwMode && !fd.Flying {
+		t.updateThrowMode(fd)
+	}
+
+	switch {
+	case !fd.Flying && (t.throwMode || fd.ThrowFlyTimer > 0):
+		return StateThrowMode
+	case !fd.Flying:
+		t.palmLanding = false
+		return StateOnGround
+	case fd.FlyMode == flyModeTakeOff:
+		return StateTakingOff
+	case fd.FlyMode == flyModeLanding && t.palmLanding:
+		return StatePalmLanding
+	case fd.FlyMode == flyModeLanding:
+		return StateLanding
+	}
+
+	t.throwMode = false
+	return StateFlying
+}
+
+// updateThrowMode ends throw mode once the drone on the ground stops
+// counting down its throw timer, or never starts it. The caller must hold
+// stateMutex.
+func (t *Tello) updateThrowMode(fd FlightData) {
+	switch {
+	case fd.ThrowFlyTimer > 0:
+		t.throwTimerSeen = true
+	case t.throwTimerSeen:
+		t.throwMode = false
+	default:
+		t.throwWait++
+		if t.throwWait >= throwModeReports {
+			t.throwMode = false
+		}
+	}
+}
+
+// setThrowMode records that the drone was told to wait to be thrown.
+func (t *Tello) setThrowMode() {
+	t.stateMutex.Lock()
+	defer t.stateMutex.Unlock()
+
+	t.throwMode = true
+	t.throwTimerSeen = false
+	t.throwWait = 0
+}
+
+// setPalmLanding records that the drone was told to land on a hand.
+func (t *Tello) setPalmLanding() {
+	t.stateMutex.Lock()
+	defer t.stateMutex.Unlock()
+
+	t.palmLanding = true
+}
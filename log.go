@@ -0,0 +1,99 @@
+package tello
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// errorQueueLen is how many background errors Errors holds.
+	errorQueueLen = 8
+
+	// DefaultErrorInterval is how often the same background error is
+	// reported.
+	DefaultErrorInterval = time.Second
+)
+
+// Logger receives diagnostic messages from the client. It is called from the
+// client's goroutines, so it must be safe for concurrent use.
+type Logger interface {
+	Log(msg string)
+}
+
+// nopLogger is the default Logger, which discards everything.
+type nopLogger struct{}
+
+func (nopLogger) Log(msg string) {}
+
+// PrintLogger is a Logger that writes to the console using println.
+type PrintLogger struct{}
+
+// Log writes msg to the console.
+func (PrintLogger) Log(msg string) {
+	println(msg)
+}
+
+// OpError is an error from one of the client's background goroutines.
+type OpError struct {
+	// Op is what the client was doing, such as "stick command".
+	Op string
+
+	Err error
+}
+
+func (e *OpError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// errorReporter rate limits background errors.
+type errorReporter struct {
+	mu       sync.Mutex
+	ch       chan error
+	interval time.Duration
+	last     map[string]reported
+}
+
+// reported is the last error reported for an Op. The error is kept as
+// text, because errors such as *net.OpError are new values every time.
+type reported struct {
+	msg string
+	at  time.Time
+}
+
+// Errors returns a channel that receives errors from the client's background
+// goroutines, as *OpError. An error with the same text from the same Op is
+// reported at most once per error interval, and errors are dropped if the
+// channel is full.
+func (t *Tello) Errors() <-chan error {
+	return t.errors.ch
+}
+
+// reportError logs a background error and sends it to Errors, unless the
+// same error was reported recently.
+func (t *Tello) reportError(op string, err error) {
+	r := &t.errors
+
+	msg := err.Error()
+
+	r.mu.Lock()
+	last, ok := r.last[op]
+	if ok && last.msg == msg && time.Since(last.at) < r.interval {
+		r.mu.Unlock()
+		return
+	}
+	r.last[op] = reported{msg: msg, at: time.Now()}
+	r.mu.Unlock()
+
+	e := &OpError{Op: op, Err: err}
+	t.logger.Log(e.Error())
+
+	select {
+	case r.ch <- e:
+	default:
+	}
+}
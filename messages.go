@@ -0,0 +1,295 @@
+package tello
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// ErrUnknownMessage is returned for a message ID that is not in the registry.
+var ErrUnknownMessage = errors.New("tello: unknown message")
+
+// Direction tells which side of the link sends a message.
+type Direction byte
+
+const (
+	// ToDrone messages are sent by the client to the drone.
+	ToDrone Direction = 1 << iota
+
+	// FromDrone messages are sent by the drone to the client.
+	FromDrone
+
+	// Both is for messages that either side can send.
+	Both = ToDrone | FromDrone
+)
+
+// PayloadLayout describes the shape of the payload carried by a message.
+type PayloadLayout byte
+
+const (
+	// LayoutNone is an empty payload.
+	LayoutNone PayloadLayout = iota
+
+	// LayoutByte is a single byte such as a flag, a mode or a flip direction.
+	LayoutByte
+
+	// LayoutUint16 is a little endian 16-bit value.
+	LayoutUint16
+
+	// LayoutSticks is the packed stick axes followed by the time of day.
+	LayoutSticks
+
+	// LayoutFlightData is the flight data status report.
+	LayoutFlightData
+
+	// LayoutWiFi is the WiFi signal strength and interference.
+	LayoutWiFi
+
+	// LayoutTime is the current date and time.
+	LayoutTime
+
+	// LayoutLog is a stream of log records.
+	LayoutLog
+
+	// LayoutString is variable length text such as a version or SSID.
+	LayoutString
+
+	// LayoutRaw is variable length data that is not decoded.
+	LayoutRaw
+)
+
+// Size returns the payload size for the layout, or -1 if it is variable.
+func (l PayloadLayout) Size() int {
+	switch l {
+	case LayoutNone:
+		return 0
+	case LayoutByte:
+		return 1
+	case LayoutUint16, LayoutWiFi:
+		return 2
+	case LayoutSticks:
+		return 11
+	case LayoutTime:
+		return 15
+	case LayoutFlightData:
+		return 24
+	}
+
+	return -1
+}
+
+// Decode decodes a payload with the layout. LayoutByte gives a byte,
+// LayoutUint16 a uint16, LayoutFlightData a FlightData, LayoutWiFi a
+// WiFiData, LayoutString a string and LayoutNone nil. Everything else is
+// returned as a copy of the payload. It returns ErrPacketLength if the
+// payload is too short.
+func (l PayloadLayout) Decode(payload []byte) (interface{}, error) {
+	if size := l.Size(); len(payload) < size {
+		return nil, ErrPacketLength
+	}
+
+	switch l {
+	case LayoutNone:
+		return nil, nil
+	case LayoutByte:
+		return payload[0], nil
+	case LayoutUint16:
+		return binary.LittleEndian.Uint16(payload), nil
+	case LayoutFlightData:
+		return ParseFlightData(payload)
+	case LayoutWiFi:
+		return WiFiData{Strength: int8(payload[0]), Disturb: int8(payload[1])}, nil
+	case LayoutString:
+		return string(payload), nil
+	}
+
+	return append([]byte(nil), payload...), nil
+}
+
+const (
+	// packetTypeSet is used for commands that change drone state.
+	packetTypeSet = 0x68
+
+	// packetTypeGet is used for queries and some commands.
+	packetTypeGet = 0x48
+
+	// packetTypeData is used for commands that are sent repeatedly without sequence.
+	packetTypeData = 0x60
+
+	// packetTypeFlip is used only by the flip command.
+	packetTypeFlip = 0x70
+
+	// packetTypeAck is used when answering a request from the drone.
+	packetTypeAck = 0x50
+
+	// packetTypeDrone is used by the drone for the messages it sends.
+	packetTypeDrone = 0x88
+)
+
+// MessageInfo describes a single message known to the Tello protocol.
+type MessageInfo struct {
+	// ID is the message ID carried in the packet header.
+	ID uint16
+
+	// Name is a short name for debug output.
+	Name string
+
+	// Direction tells which side sends the message.
+	Direction Direction
+
+	// Type is the packet type byte used when the message is sent.
+	Type byte
+
+	// Payload describes the payload carried by the message.
+	Payload PayloadLayout
+
+	// Ack is true if the drone answers the message with an acknowledgement
+	// that carries the same ID and sequence number.
+	Ack bool
+
+	// Event is published with the decoded payload when the message is
+	// received from the drone, or 0 if it is not published.
+	Event EventType
+}
+
+// messages is the registry of every known message.
+var messages = []MessageInfo{
+	{ID: ssidMessage, Name: "ssid", Direction: Both, Type: packetTypeGet, Payload: LayoutString},
+	{ID: ssidCommand, Name: "set-ssid", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutString, Ack: true},
+	{ID: ssidPasswordMessage, Name: "ssid-password", Direction: Both, Type: packetTypeGet, Payload: LayoutString},
+	{ID: ssidPasswordCommand, Name: "set-ssid-password", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutString, Ack: true},
+	{ID: wifiRegionMessage, Name: "wifi-region", Direction: Both, Type: packetTypeGet, Payload: LayoutString},
+	{ID: wifiRegionCommand, Name: "set-wifi-region", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutString, Ack: true},
+	{ID: wifiMessage, Name: "wifi", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutWiFi, Event: WiFiEvent},
+	{ID: videoEncoderRateCommand, Name: "video-encoder-rate", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: videoDynAdjRateCommand, Name: "video-dyn-adj-rate", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: eisCommand, Name: "eis", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: videoStartCommand, Name: "video-start", Direction: ToDrone, Type: packetTypeData, Payload: LayoutNone},
+	{ID: videoRateQuery, Name: "video-rate", Direction: Both, Type: packetTypeGet, Payload: LayoutByte},
+	{ID: takePictureCommand, Name: "take-picture", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutNone, Ack: true},
+	{ID: videoModeCommand, Name: "video-mode", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: videoRecordCommand, Name: "video-record", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: exposureCommand, Name: "exposure", Direction: ToDrone, Type: packetTypeGet, Payload: LayoutByte, Ack: true},
+	{ID: lightMessage, Name: "light", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutByte, Event: LightEvent},
+	{ID: jpegQualityMessage, Name: "jpeg-quality", Direction: Both, Type: packetTypeGet, Payload: LayoutByte},
+	{ID: error1Message, Name: "error-1", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutRaw},
+	{ID: error2Message, Name: "error-2", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutRaw},
+	{ID: versionMessage, Name: "version", Direction: Both, Type: packetTypeGet, Payload: LayoutString},
+	{ID: timeCommand, Name: "time", Direction: Both, Type: packetTypeAck, Payload: LayoutTime},
+	{ID: activationTimeMessage, Name: "activation-time", Direction: Both, Type: packetTypeGet, Payload: LayoutRaw},
+	{ID: loaderVersionMessage, Name: "loader-version", Direction: Both, Type: packetTypeGet, Payload: LayoutString},
+	{ID: stickCommand, Name: "stick", Direction: ToDrone, Type: packetTypeData, Payload: LayoutSticks},
+	{ID: takeoffCommand, Name: "takeoff", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutNone, Ack: true},
+	{ID: landCommand, Name: "land", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: flightMessage, Name: "flight", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutFlightData, Event: FlightDataEvent},
+	{ID: altLimitCommand, Name: "set-alt-limit", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutUint16, Ack: true},
+	{ID: flipCommand, Name: "flip", Direction: ToDrone, Type: packetTypeFlip, Payload: LayoutByte, Ack: true},
+	{ID: throwtakeoffCommand, Name: "throw-takeoff", Direction: ToDrone, Type: packetTypeGet, Payload: LayoutNone, Ack: true},
+	{ID: palmLandCommand, Name: "palm-land", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: fileSizeMessage, Name: "file-size", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutRaw},
+	{ID: fileDataMessage, Name: "file-data", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutRaw},
+	{ID: fileCompleteMessage, Name: "file-complete", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutRaw},
+	{ID: smartVideoCommand, Name: "smart-video", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: smartVideoStatusMessage, Name: "smart-video-status", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutByte},
+	{ID: logMessage, Name: "log-header", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutRaw},
+	{ID: logDataMessage, Name: "log-data", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutLog, Event: LogDataEvent},
+	{ID: logConfigMessage, Name: "log-config", Direction: FromDrone, Type: packetTypeDrone, Payload: LayoutRaw},
+	{ID: bounceCommand, Name: "bounce", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: calibrateCommand, Name: "calibrate", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: lowBatThresholdCommand, Name: "set-low-bat-threshold", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutByte, Ack: true},
+	{ID: altLimitMessage, Name: "alt-limit", Direction: Both, Type: packetTypeGet, Payload: LayoutUint16},
+	{ID: lowBatThresholdMessage, Name: "low-bat-threshold", Direction: Both, Type: packetTypeGet, Payload: LayoutByte},
+	{ID: attLimitCommand, Name: "set-att-limit", Direction: ToDrone, Type: packetTypeSet, Payload: LayoutRaw, Ack: true},
+	{ID: attLimitMessage, Name: "att-limit", Direction: Both, Type: packetTypeGet, Payload: LayoutRaw},
+}
+
+// Messages returns the registry of every known message.
+func Messages() []MessageInfo {
+	return messages
+}
+
+// LookupMessage returns the registry entry for a message ID.
+func LookupMessage(id uint16) (MessageInfo, bool) {
+	for _, m := range messages {
+		if m.ID == id {
+			return m, true
+		}
+	}
+
+	return MessageInfo{}, false
+}
+
+// LookupMessageName returns the registry entry for a message by its name.
+func LookupMessageName(name string) (MessageInfo, bool) {
+	for _, m := range messages {
+		if m.Name == name {
+			return m, true
+		}
+	}
+
+	return MessageInfo{}, false
+}
+
+// newPacket builds a packet for a message sent to the drone, using the
+// registry for the packet type and to check the payload size.
+func newPacket(id uint16, seq uint16, payload []byte) (*Packet, error) {
+	m, ok := LookupMessage(id)
+	if !ok || m.Direction&ToDrone == 0 {
+		return nil, ErrUnknownMessage
+	}
+	if size := m.Payload.Size(); size >= 0 && size != len(payload) {
+		return nil, ErrPacketLength
+	}
+
+	return &Packet{Type: m.Type, ID: id, Seq: seq, Payload: payload}, nil
+}
+
+// Validate checks a packet against the registry. Acknowledgements of
+// commands sent to the drone only need to carry a result code.
+func (p *Packet) Validate() error {
+	m, ok := LookupMessage(p.ID)
+	if !ok {
+		return ErrUnknownMessage
+	}
+
+	if m.Direction&FromDrone == 0 {
+		if m.Ack && len(p.Payload) < 1 {
+			return ErrPacketLength
+		}
+		return nil
+	}
+
+	size := m.Payload.Size()
+	switch {
+	case size < 0:
+		return nil
+	case len(p.Payload) < size:
+		return ErrPacketLength
+	}
+
+	return nil
+}
+
+// String returns a short description of the packet for debug output.
+func (p *Packet) String() string {
+	name := "unknown"
+	if m, ok := LookupMessage(p.ID); ok {
+		name = m.Name
+	}
+
+	s := name + " id=0x" + strconv.FormatUint(uint64(p.ID), 16) +
+		" type=0x" + strconv.FormatUint(uint64(p.Type), 16) +
+		" seq=" + strconv.FormatUint(uint64(p.Seq), 10) +
+		" payload=["
+	for i, b := range p.Payload {
+		if i > 0 {
+			s += " "
+		}
+		if b < 0x10 {
+			s += "0"
+		}
+		s += strconv.FormatUint(uint64(b), 16)
+	}
+
+	return s + "]"
+}
@@ -0,0 +1,66 @@
+package tello
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLayoutDecode(t *testing.T) {
+	tests := []struct {
+		layout  PayloadLayout
+		payload []byte
+		want    interface{}
+	}{
+		{LayoutNone, nil, nil},
+		{LayoutByte, []byte{0x2a}, byte(0x2a)},
+		{LayoutUint16, []byte{0x34, 0x12}, uint16(0x1234)},
+		{LayoutWiFi, []byte{90, 0xff}, WiFiData{Strength: 90, Disturb: -1}},
+		{LayoutString, []byte("v1.0"), "v1.0"},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.layout.Decode(tt.payload)
+		if err != nil {
+			t.Errorf("layout %d: %v", tt.layout, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("layout %d: got %#v, want %#v", tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutDecodeRaw(t *testing.T) {
+	payload := []byte{1, 2, 3}
+
+	got, err := LayoutLog.Decode(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, ok := got.([]byte)
+	if !ok || !bytes.Equal(b, payload) {
+		t.Fatalf("got %#v, want %#v", got, payload)
+	}
+
+	payload[0] = 0xff
+	if b[0] != 1 {
+		t.Error("decoded payload aliases the packet")
+	}
+}
+
+func TestLayoutDecodeShort(t *testing.T) {
+	for _, l := range []PayloadLayout{LayoutByte, LayoutUint16, LayoutWiFi, LayoutFlightData} {
+		if _, err := l.Decode(nil); err != ErrPacketLength {
+			t.Errorf("layout %d: got %v, want %v", l, err, ErrPacketLength)
+		}
+	}
+}
+
+func TestRegistryEvents(t *testing.T) {
+	for _, m := range Messages() {
+		if m.Event != 0 && m.Direction&FromDrone == 0 {
+			t.Errorf("%s publishes an event but is never received", m.Name)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package tello
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	packetHeaderLen = 9
+	packetCRCLen    = 2
+
+	// packetMinLen is the size of a packet with an empty payload.
+	packetMinLen = packetHeaderLen + packetCRCLen
+
+	// packetMaxLen is the largest size that fits in the 13-bit size field.
+	packetMaxLen = 0x1fff
+)
+
+var (
+	// ErrPacketTooShort is returned when data is too short to hold a packet.
+	ErrPacketTooShort = errors.New("tello: packet too short")
+
+	// ErrPacketTooLong is returned when a payload does not fit in a packet.
+	ErrPacketTooLong = errors.New("tello: packet too long")
+
+	// ErrPacketStart is returned when data does not begin with the message start byte.
+	ErrPacketStart = errors.New("tello: invalid packet start byte")
+
+	// ErrPacketLength is returned when the size in the header does not match the data.
+	ErrPacketLength = errors.New("tello: packet length mismatch")
+
+	// ErrPacketCRC8 is returned when the header checksum does not match.
+	ErrPacketCRC8 = errors.New("tello: packet header checksum mismatch")
+
+	// ErrPacketCRC16 is returned when the packet checksum does not match.
+	ErrPacketCRC16 = errors.New("tello: packet checksum mismatch")
+)
+
+// Packet is a single binary message exchanged with the Tello.
+//
+// On the wire a packet looks like this:
+//
+//	0     start byte (0xcc)
+//	1-2   packet size << 3, little endian
+//	3     CRC8 of bytes 0-2
+//	4     packet type
+//	5-6   message ID, little endian
+//	7-8   sequence number, little endian
+//	9-    payload
+//	n-2   CRC16 of everything before it, little endian
+type Packet struct {
+	Type    byte
+	ID      uint16
+	Seq     uint16
+	Payload []byte
+}
+
+// Len returns the encoded size of the packet in bytes.
+func (p *Packet) Len() int {
+	return packetMinLen + len(p.Payload)
+}
+
+// MarshalBinary encodes the packet, including both checksums.
+func (p *Packet) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, p.Len())
+	if _, err := p.encode(buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a packet from data, verifying its size and checksums.
+// The payload is copied, so data may be reused once it returns.
+func (p *Packet) UnmarshalBinary(data []byte) error {
+	if len(data) < packetMinLen {
+		return ErrPacketTooShort
+	}
+	if data[0] != messageStart {
+		return ErrPacketStart
+	}
+	if CalculateCRC8(data[0:3]) != data[3] {
+		return ErrPacketCRC8
+	}
+
+	size := int(binary.LittleEndian.Uint16(data[1:]) >> 3)
+	if size < packetMinLen || size != len(data) {
+		return ErrPacketLength
+	}
+	if CalculateCRC16(data[:size-packetCRCLen]) != binary.LittleEndian.Uint16(data[size-packetCRCLen:]) {
+		return ErrPacketCRC16
+	}
+
+	p.Type = data[4]
+	p.ID = binary.LittleEndian.Uint16(data[5:])
+	p.Seq = binary.LittleEndian.Uint16(data[7:])
+	p.Payload = append(p.Payload[:0], data[packetHeaderLen:size-packetCRCLen]...)
+
+	return nil
+}
+
+// encode writes the packet into buf and returns the number of bytes used.
+func (p *Packet) encode(buf []byte) (int, error) {
+	size := p.Len()
+	if size > packetMaxLen {
+		return 0, ErrPacketTooLong
+	}
+	if len(buf) < size {
+		return 0, ErrPacketTooShort
+	}
+
+	buf[0] = messageStart
+	binary.LittleEndian.PutUint16(buf[1:], uint16(size<<3))
+	buf[3] = CalculateCRC8(buf[0:3])
+	buf[4] = p.Type
+	binary.LittleEndian.PutUint16(buf[5:], p.ID)
+	binary.LittleEndian.PutUint16(buf[7:], p.Seq)
+	copy(buf[packetHeaderLen:], p.Payload)
+	binary.LittleEndian.PutUint16(buf[size-packetCRCLen:], CalculateCRC16(buf[:size-packetCRCLen]))
+
+	return size, nil
+}
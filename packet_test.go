@@ -0,0 +1,111 @@
+package tello
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPacketRoundTrip(t *testing.T) {
+	want := Packet{Type: packetTypeSet, ID: landCommand, Seq: 0x1234, Payload: []byte{0x00}}
+
+	data, err := want.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != want.Len() {
+		t.Fatalf("encoded %d bytes, want %d", len(data), want.Len())
+	}
+
+	var got Packet
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Type != want.Type || got.ID != want.ID || got.Seq != want.Seq ||
+		!bytes.Equal(got.Payload, want.Payload) {
+		t.Errorf("decoded %v, want %v", &got, &want)
+	}
+
+	// The payload must not alias data.
+	data[packetHeaderLen] = 0xff
+	if got.Payload[0] != 0x00 {
+		t.Error("payload aliases the encoded data")
+	}
+}
+
+func TestPacketEmptyPayload(t *testing.T) {
+	want := Packet{Type: packetTypeSet, ID: takeoffCommand, Seq: 1}
+
+	data, err := want.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != packetMinLen {
+		t.Fatalf("encoded %d bytes, want %d", len(data), packetMinLen)
+	}
+
+	var got Packet
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Payload) != 0 {
+		t.Errorf("decoded payload %x, want none", got.Payload)
+	}
+}
+
+func TestPacketTooLong(t *testing.T) {
+	p := Packet{Type: packetTypeSet, ID: ssidCommand, Payload: make([]byte, packetMaxLen)}
+
+	if _, err := p.MarshalBinary(); err != ErrPacketTooLong {
+		t.Errorf("got %v, want %v", err, ErrPacketTooLong)
+	}
+}
+
+func TestPacketEncodeShortBuffer(t *testing.T) {
+	p := Packet{Type: packetTypeSet, ID: landCommand, Payload: []byte{0x00}}
+
+	var buf [packetMinLen]byte
+	if _, err := p.encode(buf[:]); err != ErrPacketTooShort {
+		t.Errorf("got %v, want %v", err, ErrPacketTooShort)
+	}
+}
+
+func TestPacketUnmarshalErrors(t *testing.T) {
+	p := Packet{Type: packetTypeSet, ID: landCommand, Seq: 7, Payload: []byte{0x00}}
+	valid, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// corrupt returns a copy of the valid packet changed by f.
+	corrupt := func(f func(b []byte) []byte) []byte {
+		return f(append([]byte(nil), valid...))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, ErrPacketTooShort},
+		{"short", valid[:packetMinLen-1], ErrPacketTooShort},
+		{"start", corrupt(func(b []byte) []byte { b[0] = 0x55; return b }), ErrPacketStart},
+		{"crc8", corrupt(func(b []byte) []byte { b[3] ^= 0xff; return b }), ErrPacketCRC8},
+		{"crc16", corrupt(func(b []byte) []byte { b[len(b)-1] ^= 0xff; return b }), ErrPacketCRC16},
+		{"payload", corrupt(func(b []byte) []byte { b[packetHeaderLen] ^= 0xff; return b }), ErrPacketCRC16},
+		{"truncated", valid[:len(valid)-1], ErrPacketLength},
+		{"trailing", corrupt(func(b []byte) []byte { return append(b, 0x00) }), ErrPacketLength},
+		{"size too small", corrupt(func(b []byte) []byte {
+			b[1], b[2] = byte((packetMinLen-1)<<3), 0
+			b[3] = CalculateCRC8(b[0:3])
+			return b
+		}), ErrPacketLength},
+	}
+
+	for _, tt := range tests {
+		var got Packet
+		if err := got.UnmarshalBinary(tt.data); err != tt.err {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package tello
+
+import "time"
+
+// jitterGain is how much of each new sample the smoothed stick period and
+// jitter take, as a shift, the same smoothing RTP uses for jitter.
+const jitterGain = 4
+
+// StickStats describes how regularly the stick positions are being sent.
+type StickStats struct {
+	// Interval is the period the sticks are meant to be sent at.
+	Interval time.Duration
+
+	// Sent is how many stick packets have been written to the drone since
+	// Start.
+	Sent uint64
+
+	// Period is the smoothed time between stick packets.
+	Period time.Duration
+
+	// Jitter is the smoothed difference between the time between stick
+	// packets and Interval.
+	Jitter time.Duration
+
+	// MaxPeriod is the longest time between two stick packets.
+	MaxPeriod time.Duration
+
+	// Missed is how many stick packets were not sent in time, either
+	// because the sticks loop fell behind, or because a newer packet
+	// replaced one the writer had not sent yet.
+	Missed uint64
+}
+
+// SetStickInterval sets how often the stick positions are sent, such as
+// 20 * time.Millisecond for 50 Hz. The smoothed period and jitter start
+// again at the new interval. It returns ErrOutOfRange if interval is not
+// positive.
+func (t *Tello) SetStickInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return ErrOutOfRange
+	}
+
+	t.stickMutex.Lock()
+	defer t.stickMutex.Unlock()
+
+	if interval != t.stickInterval {
+		t.stickInterval = interval
+		t.stickStats.Period = 0
+		t.stickStats.Jitter = 0
+		t.lastStickSent = time.Time{}
+	}
+
+	return nil
+}
+
+// StickStats returns statistics about the stick packets sent since Start.
+func (t *Tello) StickStats() StickStats {
+	t.stickMutex.Lock()
+	defer t.stickMutex.Unlock()
+
+	stats := t.stickStats
+	stats.Interval = t.stickInterval
+
+	return stats
+}
+
+// resetStickStats clears the stick statistics. The caller must hold
+// stickMutex.
+func (t *Tello) resetStickStats() {
+	t.stickStats = StickStats{}
+	t.lastStickSent = time.Time{}
+}
+
+// recordStickSent updates the stick statistics for a packet written at now,
+// which replaced dropped packets that were never sent.
+func (t *Tello) recordStickSent(now time.Time, dropped int) {
+	t.stickMutex.Lock()
+	defer t.stickMutex.Unlock()
+
+	s := &t.stickStats
+	last := t.lastStickSent
+	t.lastStickSent = now
+	s.Sent++
+
+	if last.IsZero() {
+		s.Missed += uint64(dropped)
+		return
+	}
+
+	period := now.Sub(last)
+	if period > s.MaxPeriod {
+		s.MaxPeriod = period
+	}
+
+	// A packet that went out more than half an interval late means the
+	// ones in between were missed. Packets that were replaced in the queue
+	// fall in the same gap, so they are only counted once.
+	missed := int((period - t.stickInterval/2) / t.stickInterval)
+	if dropped > missed {
+		missed = dropped
+	}
+	s.Missed += uint64(missed)
+
+	deviation := period - t.stickInterval
+	if deviation < 0 {
+		deviation = -deviation
+	}
+
+	if s.Period == 0 {
+		s.Period = period
+		s.Jitter = deviation
+		return
+	}
+
+	s.Period += (period - s.Period) >> jitterGain
+	s.Jitter += (deviation - s.Jitter) >> jitterGain
+}
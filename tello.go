@@ -2,8 +2,9 @@
 package tello
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
-	"net"
 	"strconv"
 	"sync"
 	"time"
@@ -13,260 +14,779 @@ import (
 type Tello struct {
 	reqAddr   string
 	reqPort   string
+	localAddr string
 	respPort  string
 	videoPort string
-	conn      net.Conn
+	dialer    Dialer
+	conn      Transport
+	video     Transport
 
-	cmdMutex  sync.Mutex
-	cmdPacket [22]byte
+	// videoHandler is called with each frame received on the video port.
+	videoHandler func([]byte)
+	videoFrames  videoAssembler
 
-	seq            int16
+	// lifeMutex is held for the whole of Start and Stop, so that Start
+	// cannot add goroutines while Stop waits for the old ones.
+	lifeMutex sync.Mutex
+
+	// done is closed by Stop to end the background goroutines.
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	// connected is closed when the drone answers the connection request.
+	connected   chan struct{}
+	isConnected bool
+
+	cmdMutex sync.Mutex
+
+	// queue holds what the writer goroutine sends to the drone.
+	queue sendQueue
+
+	acks       ackTracker
+	retries    int
+	ackTimeout time.Duration
+
+	connectInterval time.Duration
+
+	// linkMutex guards when the drone was last heard from.
+	linkMutex   sync.Mutex
+	lastHeard   time.Time
+	linkLost    bool
+	linkTimeout time.Duration
+	failsafe    Failsafe
+
+	// stickMutex guards the stick positions, so that a stick packet is
+	// never sent with some axes updated and others not.
+	stickMutex     sync.Mutex
 	rx, ry, lx, ly float32
 	throttle       int
 
+	// lastStick is when the sticks were last set, for the stick timeout.
+	lastStick    time.Time
+	stickTimeout time.Duration
+
+	// stickInterval is how often the sticks are sent, and stickStats
+	// measures how often they really are.
+	stickInterval time.Duration
+	stickStats    StickStats
+	lastStickSent time.Time
+
+	events eventHub
+	logger Logger
+	errors errorReporter
+
+	eis bool
+
+	// stateMutex guards the state reported by the drone.
+	stateMutex    sync.Mutex
+	flightData    FlightData
+	hasFlightData bool
+	flightState   FlightState
+	throwMode     bool
+	palmLanding   bool
+
+	// throwTimerSeen is true once the throw timer ran in throw mode, and
+	// throwWait counts the reports on the ground without it.
+	throwTimerSeen bool
+	throwWait      int
+
+	// Flying is always false. The client has never set it.
+	//
+	// Deprecated: Use FlightState, which is safe to call from any goroutine.
 	Flying bool
 }
 
+// New returns a new Tello client that receives responses on port, using
+// the defaults for everything else. Use NewWithConfig for more control.
 func New(port string) *Tello {
-	n := &Tello{
-		reqAddr:   "192.168.10.1",
-		reqPort:   "8889",
-		respPort:  port,
-		videoPort: "11111",
-	}
-
-	return n
+	return NewWithConfig(Config{ResponsePort: port})
 }
 
 func (t *Tello) Start() (err error) {
-	reqAddr, err := net.ResolveUDPAddr("udp", t.reqAddr+":"+t.reqPort)
-	if err != nil {
-		return err
+	t.lifeMutex.Lock()
+	defer t.lifeMutex.Unlock()
+
+	t.cmdMutex.Lock()
+	defer t.cmdMutex.Unlock()
+
+	if t.conn != nil {
+		return ErrAlreadyStarted
 	}
 
-	respAddr, err := net.ResolveUDPAddr("udp", ":"+t.respPort)
+	conn, err := t.dialer.Dial(t.localAddr+":"+t.respPort, t.reqAddr+":"+t.reqPort)
 	if err != nil {
 		return err
 	}
 
-	t.conn, err = net.DialUDP("udp", respAddr, reqAddr)
-	if err != nil {
+	// send connection request using video port
+	if _, err := conn.Write([]byte(t.connectionString())); err != nil {
+		conn.Close()
 		return err
 	}
 
-	// send connection request using video port
-	if _, err := t.conn.Write([]byte(t.connectionString())); err != nil {
+	t.stickMutex.Lock()
+	t.lastStick = time.Now()
+	t.resetStickStats()
+	t.stickMutex.Unlock()
+
+	t.linkMutex.Lock()
+	t.lastHeard = time.Time{}
+	t.linkLost = false
+	t.linkMutex.Unlock()
+
+	t.stateMutex.Lock()
+	t.flightData = FlightData{}
+	t.hasFlightData = false
+	t.flightState = StateUnknown
+	t.throwMode = false
+	t.palmLanding = false
+	t.stateMutex.Unlock()
+
+	t.conn = conn
+	t.done = make(chan struct{})
+	t.connected = make(chan struct{})
+	t.isConnected = false
+	t.queue.reset()
+
+	t.wg.Add(4)
+	go t.writer(conn, t.done)
+	go t.receive(conn, t.done)
+	go t.sendSticks(t.done)
+	go t.watchdog(t.done)
+
+	return nil
+}
+
+// StartContext starts the client like Start, then waits for the drone to
+// answer the connection request. If ctx is done first, the client is stopped
+// and an error matching both ErrNotReachable and ctx.Err() is returned.
+func (t *Tello) StartContext(ctx context.Context) error {
+	if err := t.Start(); err != nil {
 		return err
 	}
 
-	go func() {
-		for {
-			err := t.SendStickCommand()
-			if err != nil {
-				println("stick command error:", err)
+	t.cmdMutex.Lock()
+	connected := t.connected
+	t.cmdMutex.Unlock()
+
+	ticker := time.NewTicker(t.connectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-connected:
+			return nil
+		case <-ctx.Done():
+			t.Stop()
+			return &contextError{err: ErrNotReachable, cause: ctx.Err()}
+		case <-ticker.C:
+			if err := t.sendConnectionRequest(); err != nil {
+				t.Stop()
+				return err
 			}
-			time.Sleep(100 * time.Millisecond)
 		}
-	}()
+	}
+}
 
-	return nil
+// sendConnectionRequest resends the connection request to the drone.
+func (t *Tello) sendConnectionRequest() error {
+	return t.queue.push(priorityNormal, outgoing{raw: []byte(t.connectionString())})
 }
 
-// TakeOff tells the Tello to takeoff
-func (t *Tello) TakeOff() (err error) {
+// Stop ends the background goroutines and closes the connection to the
+// drone. Start can be called again afterwards.
+func (t *Tello) Stop() error {
+	t.lifeMutex.Lock()
+	defer t.lifeMutex.Unlock()
+
 	t.cmdMutex.Lock()
-	defer t.cmdMutex.Unlock()
+	if t.conn == nil {
+		t.cmdMutex.Unlock()
+		return nil
+	}
+
+	t.queue.close()
+	close(t.done)
+	err := t.conn.Close()
+	t.conn = nil
+	if t.video != nil {
+		t.video.Close()
+		t.video = nil
+	}
+	t.cmdMutex.Unlock()
 
-	t.createPacketHeader(takeoffCommand, 0x68, 0)
-	t.seq++
-	binary.LittleEndian.PutUint16(t.cmdPacket[7:], uint16(t.seq))
-	binary.LittleEndian.PutUint16(t.cmdPacket[9:], CalculateCRC16(t.cmdPacket[:9]))
+	t.wg.Wait()
 
-	_, err = t.conn.Write(t.cmdPacket[:11])
+	// Reset once the receive goroutine has stopped, so that it cannot mark
+	// the client connected again.
+	t.cmdMutex.Lock()
+	t.isConnected = false
+	t.cmdMutex.Unlock()
+
+	t.linkMutex.Lock()
+	t.lastHeard = time.Time{}
+	t.linkLost = false
+	t.linkMutex.Unlock()
+
+	t.publish(DisconnectedEvent, nil)
 
 	return err
 }
 
-// Land tells the Tello to land
-func (t *Tello) Land() (err error) {
-	t.cmdMutex.Lock()
-	defer t.cmdMutex.Unlock()
+// Close is the same as Stop, so that Tello can be used as an io.Closer.
+func (t *Tello) Close() error {
+	return t.Stop()
+}
 
-	t.createPacketHeader(landCommand, 0x68, 1)
-	t.seq++
-	binary.LittleEndian.PutUint16(t.cmdPacket[7:], uint16(t.seq))
-	t.cmdPacket[9] = 0x00
-	binary.LittleEndian.PutUint16(t.cmdPacket[10:], CalculateCRC16(t.cmdPacket[:10]))
+// TakeOff tells the Tello to takeoff
+func (t *Tello) TakeOff() (err error) {
+	if err := t.checkFlying(false, true); err != nil {
+		return err
+	}
 
-	_, err = t.conn.Write(t.cmdPacket[:12])
+	return t.sendAndWait(takeoffCommand, nil)
+}
 
-	return err
+// Land tells the Tello to land
+func (t *Tello) Land() (err error) {
+	return t.sendAndWait(landCommand, []byte{0x00})
 }
 
 // Up tells the drone to ascend. Pass in an int from 0-100.
 func (t *Tello) Up(val int) error {
-	t.ly = float32(val) / 100.0
-	return nil
+	v, err := validatePitch(val)
+
+	t.stickMutex.Lock()
+	t.ly = float32(v) / 100.0
+	t.lastStick = time.Now()
+	t.stickMutex.Unlock()
+
+	return err
 }
 
 // Down tells the drone to descend. Pass in an int from 0-100.
 func (t *Tello) Down(val int) error {
-	t.ly = float32(val) / 100.0 * -1.0
-	return nil
+	v, err := validatePitch(val)
+
+	t.stickMutex.Lock()
+	t.ly = float32(v) / 100.0 * -1.0
+	t.lastStick = time.Now()
+	t.stickMutex.Unlock()
+
+	return err
 }
 
 // Forward tells the drone to go forward. Pass in an int from 0-100.
 func (t *Tello) Forward(val int) error {
-	t.ry = float32(val) / 100.0
-	return nil
+	v, err := validatePitch(val)
+
+	t.stickMutex.Lock()
+	t.ry = float32(v) / 100.0
+	t.lastStick = time.Now()
+	t.stickMutex.Unlock()
+
+	return err
 }
 
 // Backward tells drone to go in reverse. Pass in an int from 0-100.
 func (t *Tello) Backward(val int) error {
-	t.ry = float32(val) / 100.0 * -1.0
-	return nil
+	v, err := validatePitch(val)
+
+	t.stickMutex.Lock()
+	t.ry = float32(v) / 100.0 * -1.0
+	t.lastStick = time.Now()
+	t.stickMutex.Unlock()
+
+	return err
 }
 
 // Right tells drone to go right. Pass in an int from 0-100.
 func (t *Tello) Right(val int) error {
-	t.rx = float32(val) / 100.0
-	return nil
+	v, err := validatePitch(val)
+
+	t.stickMutex.Lock()
+	t.rx = float32(v) / 100.0
+	t.lastStick = time.Now()
+	t.stickMutex.Unlock()
+
+	return err
 }
 
 // Left tells drone to go left. Pass in an int from 0-100.
 func (t *Tello) Left(val int) error {
-	t.rx = float32(val) / 100.0 * -1.0
-	return nil
+	v, err := validatePitch(val)
+
+	t.stickMutex.Lock()
+	t.rx = float32(v) / 100.0 * -1.0
+	t.lastStick = time.Now()
+	t.stickMutex.Unlock()
+
+	return err
 }
 
 // Clockwise tells drone to rotate in a clockwise direction. Pass in an int from 0-100.
 func (t *Tello) Clockwise(val int) error {
-	t.lx = float32(val) / 100.0
-	return nil
+	v, err := validatePitch(val)
+
+	t.stickMutex.Lock()
+	t.lx = float32(v) / 100.0
+	t.lastStick = time.Now()
+	t.stickMutex.Unlock()
+
+	return err
 }
 
 // CounterClockwise tells drone to rotate in a counter-clockwise direction.
 // Pass in an int from 0-100.
 func (t *Tello) CounterClockwise(val int) error {
-	t.lx = float32(val) / 100.0 * -1.0
-	return nil
+	v, err := validatePitch(val)
+
+	t.stickMutex.Lock()
+	t.lx = float32(v) / 100.0 * -1.0
+	t.lastStick = time.Now()
+	t.stickMutex.Unlock()
+
+	return err
 }
 
-// Throw & Go support
-func (t *Tello) ThrowTakeOff() error {
-	t.cmdMutex.Lock()
-	defer t.cmdMutex.Unlock()
+// SetSticks sets all four stick axes at once, so that they are sent to the
+// drone in the same stick packet. Each value is from -100 to 100. rx moves
+// right, ry moves forward, lx rotates clockwise and ly ascends, with
+// negative values going the other way.
+func (t *Tello) SetSticks(rx, ry, lx, ly int) (err error) {
+	for _, v := range [...]*int{&rx, &ry, &lx, &ly} {
+		var e error
+		if *v, e = validateAxis(*v); e != nil {
+			err = e
+		}
+	}
 
-	t.createPacketHeader(throwtakeoffCommand, 0x48, 0)
-	t.seq++
-	binary.LittleEndian.PutUint16(t.cmdPacket[7:], uint16(t.seq))
-	binary.LittleEndian.PutUint16(t.cmdPacket[9:], CalculateCRC16(t.cmdPacket[:9]))
+	t.stickMutex.Lock()
+	defer t.stickMutex.Unlock()
 
-	_, err := t.conn.Write(t.cmdPacket[:11])
+	t.rx = float32(rx) / 100.0
+	t.ry = float32(ry) / 100.0
+	t.lx = float32(lx) / 100.0
+	t.ly = float32(ly) / 100.0
+	t.lastStick = time.Now()
 
 	return err
 }
 
-// PalmLand tells drone to come in for a landing on the palm of your hand.
-func (t *Tello) PalmLand() error {
-	t.cmdMutex.Lock()
-	defer t.cmdMutex.Unlock()
+// Throw & Go support
+func (t *Tello) ThrowTakeOff() error {
+	if err := t.checkFlying(false, true); err != nil {
+		return err
+	}
 
-	t.createPacketHeader(palmLandCommand, 0x68, 1)
-	t.seq++
-	binary.LittleEndian.PutUint16(t.cmdPacket[7:], uint16(t.seq))
-	t.cmdPacket[9] = 0x00
-	binary.LittleEndian.PutUint16(t.cmdPacket[10:], CalculateCRC16(t.cmdPacket[:10]))
+	if err := t.sendAndWait(throwtakeoffCommand, nil); err != nil {
+		return err
+	}
 
-	_, err := t.conn.Write(t.cmdPacket[:12])
+	t.setThrowMode()
+	return nil
+}
 
-	return err
+// PalmLand tells drone to come in for a landing on the palm of your hand.
+func (t *Tello) PalmLand() error {
+	if err := t.sendAndWait(palmLandCommand, []byte{0x00}); err != nil {
+		return err
+	}
+
+	t.setPalmLanding()
+	return nil
 }
 
 // Flip tells drone to flip
 func (t *Tello) Flip(direction FlipType) error {
+	if err := t.checkFlying(true, true); err != nil {
+		return err
+	}
+
+	return t.sendAndWait(flipCommand, []byte{byte(direction)})
+}
+
+// StartVideo tells Tello to send start info (SPS/PPS) for video stream.
+// The first call also starts listening on the video port.
+func (t *Tello) StartVideo() (err error) {
 	t.cmdMutex.Lock()
 	defer t.cmdMutex.Unlock()
 
-	t.createPacketHeader(flipCommand, 0x70, 1)
-	t.seq++
-	binary.LittleEndian.PutUint16(t.cmdPacket[7:], uint16(t.seq))
-	t.cmdPacket[9] = byte(direction)
-	binary.LittleEndian.PutUint16(t.cmdPacket[10:], CalculateCRC16(t.cmdPacket[:10]))
+	if t.conn != nil && t.video == nil {
+		t.video, err = t.dialer.Listen(t.localAddr + ":" + t.videoPort)
+		if err != nil {
+			return err
+		}
 
-	_, err := t.conn.Write(t.cmdPacket[:12])
+		t.wg.Add(1)
+		go t.receiveVideo(t.video, t.done)
+	}
 
-	return err
+	return t.sendCommand(videoStartCommand, nil)
 }
 
-// StartVideo tells Tello to send start info (SPS/PPS) for video stream.
-func (t *Tello) StartVideo() (err error) {
+// SetVideoHandler sets the function that is called with the H.264 data of
+// each frame received on the video port. It is called from the goroutine
+// that reads the video, so it should return quickly, and the data is only
+// valid until it returns.
+func (t *Tello) SetVideoHandler(handler func([]byte)) {
 	t.cmdMutex.Lock()
 	defer t.cmdMutex.Unlock()
 
-	t.createPacketHeader(videoStartCommand, 0x60, 0)
-	binary.LittleEndian.PutUint16(t.cmdPacket[7:], 0x00) // seq = 0
-	binary.LittleEndian.PutUint16(t.cmdPacket[9:], CalculateCRC16(t.cmdPacket[:9]))
+	t.videoHandler = handler
+}
 
-	_, err = t.conn.Write(t.cmdPacket[:11])
+// SetEIS turns electronic image stabilization for the video stream on or off.
+func (t *Tello) SetEIS(on bool) error {
+	val := byte(0x00)
+	if on {
+		val = 0x01
+	}
 
-	return err
+	if err := t.sendAndWait(eisCommand, []byte{val}); err != nil {
+		return err
+	}
+
+	t.cmdMutex.Lock()
+	t.eis = on
+	t.cmdMutex.Unlock()
+
+	return nil
 }
 
-func (t *Tello) SendStickCommand() (err error) {
+// EIS returns true if electronic image stabilization was last set to on.
+func (t *Tello) EIS() bool {
 	t.cmdMutex.Lock()
 	defer t.cmdMutex.Unlock()
 
-	t.createPacketHeader(stickCommand, 0x60, 11)
-	binary.LittleEndian.PutUint16(t.cmdPacket[7:], 0x00) // seq = 0
+	return t.eis
+}
+
+func (t *Tello) SendStickCommand() (err error) {
+	t.stickMutex.Lock()
+	rx, ry, lx, ly, throttle := t.rx, t.ry, t.lx, t.ly, t.throttle
+	t.stickMutex.Unlock()
 
 	// All axes range from 364 to 1684
 	// RightX left =364 right =1684
-	axis1 := int16(660.0*t.rx + 1024.0)
+	axis1 := stickAxis(rx)
 
 	// RightY down =364 up =1684
-	axis2 := int16(660.0*t.ry + 1024.0)
+	axis2 := stickAxis(ry)
 
 	// LeftY down =364 up =1684
-	axis3 := int16(660.0*t.ly + 1024.0)
+	axis3 := stickAxis(ly)
 
 	// LeftX left =364 right =1684
-	axis4 := int16(660.0*t.lx + 1024.0)
+	axis4 := stickAxis(lx)
 
 	// speed control
-	axis5 := int16(t.throttle)
+	axis5 := int16(throttle)
 
+	var payload [11]byte
 	packedAxis := int64(axis1)&0x7FF | int64(axis2&0x7FF)<<11 | int64(axis3&0x7FF)<<22 | int64(axis4&0x7FF)<<33 | int64(axis5)<<44
-	t.cmdPacket[9] = byte(0xFF & packedAxis)
-	t.cmdPacket[10] = byte(packedAxis >> 8)
-	t.cmdPacket[11] = byte(packedAxis >> 16)
-	t.cmdPacket[12] = byte(packedAxis >> 24)
-	t.cmdPacket[13] = byte(packedAxis >> 32)
-	t.cmdPacket[14] = byte(packedAxis >> 40)
+	payload[0] = byte(0xFF & packedAxis)
+	payload[1] = byte(packedAxis >> 8)
+	payload[2] = byte(packedAxis >> 16)
+	payload[3] = byte(packedAxis >> 24)
+	payload[4] = byte(packedAxis >> 32)
+	payload[5] = byte(packedAxis >> 40)
 
 	now := time.Now()
-	t.cmdPacket[15] = byte(now.Hour())
-	t.cmdPacket[16] = byte(now.Minute())
-	t.cmdPacket[17] = byte(now.Second())
-	t.cmdPacket[18] = byte(now.UnixNano() / int64(time.Millisecond) & 0xff)
-	t.cmdPacket[19] = byte(now.UnixNano() / int64(time.Millisecond) >> 8)
+	payload[6] = byte(now.Hour())
+	payload[7] = byte(now.Minute())
+	payload[8] = byte(now.Second())
+	payload[9] = byte(now.UnixNano() / int64(time.Millisecond) & 0xff)
+	payload[10] = byte(now.UnixNano() / int64(time.Millisecond) >> 8)
 
-	binary.LittleEndian.PutUint16(t.cmdPacket[20:], CalculateCRC16(t.cmdPacket[:20]))
+	pkt, err := newPacket(stickCommand, t.acks.sequence(), payload[:])
+	if err != nil {
+		return err
+	}
 
-	_, err = t.conn.Write(t.cmdPacket[:22])
-	return
+	return t.queue.setSticks(pkt)
 }
 
-func (t *Tello) createPacketHeader(cmd int16, pktType byte, len int16) (err error) {
-	l := len + 11
+// sendCommand builds a packet for a registered message that is not
+// acknowledged and queues it to be sent to the drone.
+func (t *Tello) sendCommand(id uint16, payload []byte) error {
+	pkt, err := newPacket(id, t.acks.sequence(), payload)
+	if err != nil {
+		return err
+	}
 
-	t.cmdPacket[0] = byte(messageStart)
-	binary.LittleEndian.PutUint16(t.cmdPacket[1:], uint16(l<<3))
-	t.cmdPacket[3] = CalculateCRC8(t.cmdPacket[0:3])
-	t.cmdPacket[4] = pktType
-	binary.LittleEndian.PutUint16(t.cmdPacket[5:], uint16(cmd))
+	return t.sendPacket(pkt)
+}
 
-	return nil
+// sendPacket queues pkt to be sent to the drone by the writer goroutine.
+func (t *Tello) sendPacket(pkt *Packet) error {
+	return t.queue.push(commandPriority(pkt.ID), outgoing{pkt: pkt})
+}
+
+// sendSticks sends the stick positions to the drone every stick interval
+// until done is closed.
+func (t *Tello) sendSticks(done chan struct{}) {
+	defer t.wg.Done()
+
+	t.stickMutex.Lock()
+	interval := t.stickInterval
+	t.stickMutex.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		t.checkStickTimeout()
+
+		err := t.SendStickCommand()
+		if err != nil {
+			t.reportError("stick command", err)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		t.stickMutex.Lock()
+		if t.stickInterval != interval {
+			interval = t.stickInterval
+			ticker.Reset(interval)
+		}
+		t.stickMutex.Unlock()
+	}
+}
+
+// checkStickTimeout centers the sticks if they have not been set within the
+// stick timeout, so that the drone stops if the application hangs.
+func (t *Tello) checkStickTimeout() {
+	t.stickMutex.Lock()
+	expired := t.stickTimeout > 0 && time.Since(t.lastStick) > t.stickTimeout &&
+		(t.rx != 0 || t.ry != 0 || t.lx != 0 || t.ly != 0)
+	if expired {
+		t.rx, t.ry, t.lx, t.ly = 0, 0, 0, 0
+	}
+	t.stickMutex.Unlock()
+
+	if expired {
+		t.publish(StickTimeoutEvent, nil)
+	}
+}
+
+// receive reads responses from the drone until done is closed.
+func (t *Tello) receive(conn Transport, done chan struct{}) {
+	defer t.wg.Done()
+
+	var buf [2048]byte
+	var pkt Packet
+
+	for {
+		n, err := conn.Read(buf[:])
+		if err != nil {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			t.reportError("receive", err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		t.heard()
+		t.handleResponse(&pkt, buf[:n])
+	}
+}
+
+// heard records that something was received from the drone.
+func (t *Tello) heard() {
+	t.linkMutex.Lock()
+	restored := t.linkLost
+	t.lastHeard = time.Now()
+	t.linkLost = false
+	t.linkMutex.Unlock()
+
+	if restored {
+		t.publish(ConnectionRestoredEvent, nil)
+	}
+}
+
+// watchdog checks the link to the drone until done is closed.
+func (t *Tello) watchdog(done chan struct{}) {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.connectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		t.checkLink()
+	}
+}
+
+// checkLink looks for the connection being lost once the drone has been
+// heard from. While it is lost, the connection request is resent.
+func (t *Tello) checkLink() {
+	t.linkMutex.Lock()
+	if t.linkTimeout < 0 || t.lastHeard.IsZero() {
+		t.linkMutex.Unlock()
+		return
+	}
+
+	lost := !t.linkLost && time.Since(t.lastHeard) > t.linkTimeout
+	if lost {
+		t.linkLost = true
+	}
+	reconnect := t.linkLost
+	t.linkMutex.Unlock()
+
+	if lost {
+		t.publish(ConnectionLostEvent, nil)
+
+		switch t.failsafe {
+		case FailsafeHover:
+			t.SetSticks(0, 0, 0, 0)
+		case FailsafeLand:
+			t.SetSticks(0, 0, 0, 0)
+			t.LandAsync()
+		}
+	}
+
+	if reconnect {
+		if err := t.sendConnectionRequest(); err != nil {
+			t.reportError("reconnect", err)
+		}
+	}
+}
+
+// Connected returns true if the drone has answered the connection request
+// and the connection has not been lost since.
+func (t *Tello) Connected() bool {
+	t.cmdMutex.Lock()
+	connected := t.isConnected
+	t.cmdMutex.Unlock()
+
+	t.linkMutex.Lock()
+	defer t.linkMutex.Unlock()
+
+	return connected && !t.linkLost
+}
+
+// receiveVideo reads video datagrams until done is closed.
+func (t *Tello) receiveVideo(video Transport, done chan struct{}) {
+	defer t.wg.Done()
+
+	var buf [2048]byte
+
+	for {
+		n, err := video.Read(buf[:])
+		if err != nil {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			t.reportError("video receive", err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		frame, ok := t.videoFrames.add(buf[:n])
+		if !ok {
+			continue
+		}
+
+		t.cmdMutex.Lock()
+		handler := t.videoHandler
+		t.cmdMutex.Unlock()
+
+		if handler != nil {
+			handler(frame)
+		}
+
+		if t.subscribed(VideoFrameEvent) {
+			t.publish(VideoFrameEvent, append([]byte(nil), frame...))
+		}
+	}
+}
+
+// handleResponse decodes a single datagram from the drone.
+func (t *Tello) handleResponse(pkt *Packet, data []byte) {
+	if len(data) == 0 || data[0] != messageStart {
+		if bytes.HasPrefix(data, []byte("conn_ack")) {
+			t.setConnected()
+		}
+		return
+	}
+
+	if err := pkt.UnmarshalBinary(data); err != nil {
+		t.logger.Log("invalid packet: " + err.Error())
+		return
+	}
+
+	if err := pkt.Validate(); err != nil {
+		t.logger.Log(err.Error() + ": " + pkt.String())
+		return
+	}
+
+	m, _ := LookupMessage(pkt.ID)
+	if m.Ack && m.Direction&ToDrone != 0 {
+		switch t.acks.resolve(pkt.ID, pkt.Seq) {
+		case ackDuplicate:
+			t.logger.Log("duplicate ack: " + pkt.String())
+			return
+		case ackStale:
+			t.logger.Log("stale ack: " + pkt.String())
+			return
+		case ackUnknown:
+			t.logger.Log("unexpected ack: " + pkt.String())
+			return
+		}
+
+		switch pkt.ID {
+		case takeoffCommand, throwtakeoffCommand:
+			t.publish(TakeOffEvent, nil)
+		case landCommand, palmLandCommand:
+			t.publish(LandingEvent, nil)
+		}
+		return
+	}
+
+	// Flight data is always decoded, because it drives the flight state.
+	if m.Event == 0 || (m.Payload != LayoutFlightData && !t.subscribed(m.Event)) {
+		return
+	}
+
+	decoded, err := m.Payload.Decode(pkt.Payload)
+	if err != nil {
+		t.logger.Log(err.Error() + ": " + pkt.String())
+		return
+	}
+
+	if fd, ok := decoded.(FlightData); ok {
+		t.setFlightData(fd)
+	}
+	t.publish(m.Event, decoded)
+}
+
+// setConnected records that the drone answered the connection request.
+func (t *Tello) setConnected() {
+	t.cmdMutex.Lock()
+	defer t.cmdMutex.Unlock()
+
+	if !t.isConnected {
+		t.isConnected = true
+		close(t.connected)
+		t.publish(ConnectedEvent, nil)
+	}
 }
 
 func (t *Tello) connectionString() string {
@@ -276,12 +796,36 @@ func (t *Tello) connectionString() string {
 	return string(msg)
 }
 
-func validatePitch(val int) int {
+// validatePitch clamps val to 0-100, returning ErrOutOfRange if it was outside.
+func validatePitch(val int) (int, error) {
 	if val > 100 {
-		return 100
+		return 100, ErrOutOfRange
 	} else if val < 0 {
-		return 0
+		return 0, ErrOutOfRange
+	}
+
+	return val, nil
+}
+
+// validateAxis clamps val to -100-100, returning ErrOutOfRange if it was outside.
+func validateAxis(val int) (int, error) {
+	if val > 100 {
+		return 100, ErrOutOfRange
+	} else if val < -100 {
+		return -100, ErrOutOfRange
+	}
+
+	return val, nil
+}
+
+// stickAxis converts a stick position from -1 to 1 into the value sent to
+// the drone, which always stays within 364 to 1684.
+func stickAxis(val float32) int16 {
+	if val > 1 {
+		val = 1
+	} else if val < -1 {
+		val = -1
 	}
 
-	return val
+	return int16(660.0*val + 1024.0)
 }
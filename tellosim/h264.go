@@ -0,0 +1,191 @@
+package tellosim
+
+// The default video is a tiny H.264 baseline stream that needs no encoder:
+// a grey IDR picture made of uncompressed I_PCM macroblocks, followed by
+// P pictures where every macroblock is skipped.
+
+const (
+	// videoWidthMBs and videoHeightMBs are the picture size in macroblocks.
+	videoWidthMBs  = 2
+	videoHeightMBs = 2
+
+	// videoGOP is how many pictures there are from one IDR to the next.
+	videoGOP = 15
+)
+
+// bitWriter writes the bits of an H.264 RBSP.
+type bitWriter struct {
+	buf   []byte
+	nbits int
+}
+
+func (w *bitWriter) bit(b uint) {
+	if w.nbits%8 == 0 {
+		w.buf = append(w.buf, 0)
+	}
+	if b != 0 {
+		w.buf[len(w.buf)-1] |= 0x80 >> (w.nbits % 8)
+	}
+	w.nbits++
+}
+
+// u writes v as an n bit unsigned value.
+func (w *bitWriter) u(n int, v uint) {
+	for i := n - 1; i >= 0; i-- {
+		w.bit((v >> i) & 1)
+	}
+}
+
+// ue writes v as an unsigned Exp-Golomb code.
+func (w *bitWriter) ue(v uint) {
+	v++
+	n := 0
+	for x := v; x > 1; x >>= 1 {
+		n++
+	}
+	w.u(n, 0)
+	w.u(n+1, v)
+}
+
+// se writes v as a signed Exp-Golomb code.
+func (w *bitWriter) se(v int) {
+	if v > 0 {
+		w.ue(uint(2*v - 1))
+	} else {
+		w.ue(uint(-2 * v))
+	}
+}
+
+// align writes zero bits up to the next byte.
+func (w *bitWriter) align() {
+	for w.nbits%8 != 0 {
+		w.bit(0)
+	}
+}
+
+// trailing writes the RBSP stop bit and aligns.
+func (w *bitWriter) trailing() {
+	w.bit(1)
+	w.align()
+}
+
+// nal wraps an RBSP in a start code and NAL header, adding emulation
+// prevention bytes.
+func nal(header byte, rbsp []byte) []byte {
+	out := []byte{0, 0, 0, 1, header}
+
+	zeros := 0
+	for _, b := range rbsp {
+		if zeros >= 2 && b <= 3 {
+			out = append(out, 3)
+			zeros = 0
+		}
+		out = append(out, b)
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+	}
+
+	return out
+}
+
+// sps returns the sequence parameter set.
+func sps() []byte {
+	var w bitWriter
+	w.u(8, 66)               // baseline profile
+	w.u(8, 0)                // constraint flags
+	w.u(8, 30)               // level 3.0
+	w.ue(0)                  // seq_parameter_set_id
+	w.ue(0)                  // log2_max_frame_num_minus4
+	w.ue(2)                  // pic_order_cnt_type
+	w.ue(1)                  // max_num_ref_frames
+	w.u(1, 0)                // gaps_in_frame_num_value_allowed_flag
+	w.ue(videoWidthMBs - 1)  // pic_width_in_mbs_minus1
+	w.ue(videoHeightMBs - 1) // pic_height_in_map_units_minus1
+	w.u(1, 1)                // frame_mbs_only_flag
+	w.u(1, 1)                // direct_8x8_inference_flag
+	w.u(1, 0)                // frame_cropping_flag
+	w.u(1, 0)                // vui_parameters_present_flag
+	w.trailing()
+
+	return nal(0x67, w.buf)
+}
+
+// pps returns the picture parameter set.
+func pps() []byte {
+	var w bitWriter
+	w.ue(0)   // pic_parameter_set_id
+	w.ue(0)   // seq_parameter_set_id
+	w.u(1, 0) // entropy_coding_mode_flag
+	w.u(1, 0) // bottom_field_pic_order_in_frame_present_flag
+	w.ue(0)   // num_slice_groups_minus1
+	w.ue(0)   // num_ref_idx_l0_default_active_minus1
+	w.ue(0)   // num_ref_idx_l1_default_active_minus1
+	w.u(1, 0) // weighted_pred_flag
+	w.u(2, 0) // weighted_bipred_idc
+	w.se(0)   // pic_init_qp_minus26
+	w.se(0)   // pic_init_qs_minus26
+	w.se(0)   // chroma_qp_index_offset
+	w.u(1, 0) // deblocking_filter_control_present_flag
+	w.u(1, 0) // constrained_intra_pred_flag
+	w.u(1, 0) // redundant_pic_cnt_present_flag
+	w.trailing()
+
+	return nal(0x68, w.buf)
+}
+
+// idr returns a grey IDR picture coded with I_PCM macroblocks.
+func idr(idrPicID uint) []byte {
+	var w bitWriter
+	w.ue(0)        // first_mb_in_slice
+	w.ue(7)        // slice_type I
+	w.ue(0)        // pic_parameter_set_id
+	w.u(4, 0)      // frame_num
+	w.ue(idrPicID) // idr_pic_id
+	w.u(1, 0)      // no_output_of_prior_pics_flag
+	w.u(1, 0)      // long_term_reference_flag
+	w.se(0)        // slice_qp_delta
+
+	for mb := 0; mb < videoWidthMBs*videoHeightMBs; mb++ {
+		w.ue(25) // mb_type I_PCM
+		w.align()
+		for i := 0; i < 256+2*64; i++ {
+			w.u(8, 0x80)
+		}
+	}
+	w.trailing()
+
+	return nal(0x65, w.buf)
+}
+
+// skipped returns a P picture where every macroblock is skipped.
+func skipped(frameNum uint) []byte {
+	var w bitWriter
+	w.ue(0)             // first_mb_in_slice
+	w.ue(5)             // slice_type P
+	w.ue(0)             // pic_parameter_set_id
+	w.u(4, frameNum%16) // frame_num
+	w.u(1, 0)           // num_ref_idx_active_override_flag
+	w.u(1, 0)           // ref_pic_list_modification_flag_l0
+	w.u(1, 0)           // adaptive_ref_pic_marking_mode_flag
+	w.se(0)             // slice_qp_delta
+
+	// mb_skip_run covers the whole picture
+	w.ue(videoWidthMBs * videoHeightMBs)
+	w.trailing()
+
+	return nal(0x41, w.buf)
+}
+
+// defaultVideo returns the default stream as an Annex B elementary stream.
+func defaultVideo() []byte {
+	stream := append(sps(), pps()...)
+	stream = append(stream, idr(0)...)
+	for i := 1; i < videoGOP; i++ {
+		stream = append(stream, skipped(uint(i))...)
+	}
+
+	return stream
+}
@@ -0,0 +1,314 @@
+package tellosim
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	tello "github.com/hybridgroup/tinygo-tello"
+)
+
+// reorderHold is the longest a datagram is held back to reorder it when
+// nothing else is sent after it.
+const reorderHold = 50 * time.Millisecond
+
+// Impairment describes the faults injected into a transport. Probabilities
+// range from 0 to 1.
+//
+// Faults are decided in the order datagrams are impaired, so the same Seed
+// gives the same faults to the same sequence of impaired datagrams in each
+// direction. Datagrams left out by Filter do not use random numbers, so
+// leaving out timer driven traffic such as sticks, telemetry and video
+// makes the faults independent of timing. The only exception is a datagram
+// held back for reordering, which is released after reorderHold if nothing
+// else is impaired after it.
+type Impairment struct {
+	// Loss is the probability that a datagram is dropped.
+	Loss float64
+
+	// Duplicate is the probability that a datagram is delivered twice.
+	Duplicate float64
+
+	// Reorder is the probability that a datagram is held back and
+	// delivered after the one that follows it.
+	Reorder float64
+
+	// Delay is added to every datagram.
+	Delay time.Duration
+
+	// Jitter is the most random delay added on top of Delay.
+	Jitter time.Duration
+
+	// Seed seeds the random numbers.
+	Seed int64
+
+	// Filter picks the datagrams to impair. Others are delivered at once.
+	// Nil impairs every datagram.
+	Filter func(b []byte) bool
+}
+
+// MessageFilter returns a Filter that picks the tello packets with one of
+// the message IDs.
+func MessageFilter(ids ...uint16) func(b []byte) bool {
+	return func(b []byte) bool {
+		var pkt tello.Packet
+		if err := pkt.UnmarshalBinary(b); err != nil {
+			return false
+		}
+
+		for _, id := range ids {
+			if pkt.ID == id {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// ImpairDialer wraps d so that every transport it opens is impaired in both
+// directions.
+func ImpairDialer(d tello.Dialer, imp Impairment) tello.Dialer {
+	return &impairedDialer{d: d, imp: imp}
+}
+
+type impairedDialer struct {
+	d   tello.Dialer
+	imp Impairment
+
+	mu    sync.Mutex
+	count int64
+}
+
+// next returns the impairment for the next transport, with its own seed.
+func (d *impairedDialer) next() Impairment {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	imp := d.imp
+	imp.Seed += d.count * 2
+	d.count++
+
+	return imp
+}
+
+func (d *impairedDialer) Dial(laddr, raddr string) (tello.Transport, error) {
+	t, err := d.d.Dial(laddr, raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return ImpairTransport(t, d.next()), nil
+}
+
+func (d *impairedDialer) Listen(laddr string) (tello.Transport, error) {
+	t, err := d.d.Listen(laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return ImpairTransport(t, d.next()), nil
+}
+
+// ImpairTransport wraps t so that datagrams written to it and read from it
+// are impaired. Each direction has its own random numbers.
+func ImpairTransport(t tello.Transport, imp Impairment) tello.Transport {
+	it := &impairedTransport{
+		t:    t,
+		in:   make(chan []byte, impairQueueLen),
+		done: make(chan struct{}),
+	}
+
+	it.out = newImpairedLink(imp, imp.Seed, func(b []byte) {
+		t.Write(b)
+	})
+	it.recv = newImpairedLink(imp, imp.Seed+1, func(b []byte) {
+		select {
+		case it.in <- b:
+		case <-it.done:
+		}
+	})
+
+	go it.receive()
+
+	return it
+}
+
+// impairQueueLen is how many received datagrams are held before reading
+// from the wrapped transport stops.
+const impairQueueLen = 32
+
+type impairedTransport struct {
+	t         tello.Transport
+	out, recv *impairedLink
+	in        chan []byte
+	done      chan struct{}
+	once      sync.Once
+}
+
+// receive reads from the wrapped transport until it is closed.
+func (it *impairedTransport) receive() {
+	var buf [2048]byte
+
+	for {
+		n, err := it.t.Read(buf[:])
+		if err != nil {
+			it.Close()
+			return
+		}
+
+		it.recv.send(append([]byte(nil), buf[:n]...))
+	}
+}
+
+func (it *impairedTransport) Read(b []byte) (int, error) {
+	select {
+	case d := <-it.in:
+		return copy(b, d), nil
+	case <-it.done:
+		return 0, tello.ErrClosed
+	}
+}
+
+func (it *impairedTransport) Write(b []byte) (int, error) {
+	select {
+	case <-it.done:
+		return 0, tello.ErrClosed
+	default:
+	}
+
+	it.out.send(append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func (it *impairedTransport) Close() error {
+	var err error
+	it.once.Do(func() {
+		close(it.done)
+		err = it.t.Close()
+	})
+
+	return err
+}
+
+// impairedLink injects faults into one direction of a transport.
+type impairedLink struct {
+	imp     Impairment
+	deliver func([]byte)
+
+	mu        sync.Mutex
+	rng       *rand.Rand
+	held      []byte
+	heldDelay time.Duration
+	timer     *time.Timer
+
+	// holds counts the datagrams held back, so that a timer that fires
+	// late does not release a later one.
+	holds int
+}
+
+func newImpairedLink(imp Impairment, seed int64, deliver func([]byte)) *impairedLink {
+	return &impairedLink{
+		imp:     imp,
+		deliver: deliver,
+		rng:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// delivery is a datagram waiting to be delivered.
+type delivery struct {
+	data  []byte
+	delay time.Duration
+}
+
+// send decides what happens to b and schedules its delivery.
+func (l *impairedLink) send(b []byte) {
+	if l.imp.Filter != nil && !l.imp.Filter(b) {
+		l.deliver(b)
+		return
+	}
+
+	l.mu.Lock()
+	out := l.plan(b)
+	l.mu.Unlock()
+
+	for _, d := range out {
+		l.schedule(d)
+	}
+}
+
+// plan returns the deliveries for b. The caller must hold mu.
+func (l *impairedLink) plan(b []byte) []delivery {
+	if l.rng.Float64() < l.imp.Loss {
+		return nil
+	}
+
+	copies := 1
+	if l.rng.Float64() < l.imp.Duplicate {
+		copies = 2
+	}
+
+	// The reorder draw is always made, so that the random numbers do not
+	// depend on whether a held datagram was already released.
+	reorder := l.rng.Float64() < l.imp.Reorder
+	if reorder && l.held == nil {
+		l.held = b
+		l.heldDelay = l.delay()
+		l.holds++
+		hold := l.holds
+		l.timer = time.AfterFunc(reorderHold, func() {
+			l.release(hold)
+		})
+		return nil
+	}
+
+	var out []delivery
+	for i := 0; i < copies; i++ {
+		out = append(out, delivery{b, l.delay()})
+	}
+
+	if l.held != nil {
+		l.timer.Stop()
+		out = append(out, delivery{l.held, l.heldDelay})
+		l.held = nil
+	}
+
+	return out
+}
+
+// release delivers a held datagram that nothing else was sent after.
+func (l *impairedLink) release(hold int) {
+	l.mu.Lock()
+	held := l.held
+	if held == nil || hold != l.holds {
+		l.mu.Unlock()
+		return
+	}
+	l.held = nil
+	delay := l.heldDelay
+	l.mu.Unlock()
+
+	l.schedule(delivery{held, delay})
+}
+
+// delay returns the delay for the next datagram. The caller must hold mu.
+func (l *impairedLink) delay() time.Duration {
+	delay := l.imp.Delay
+	if l.imp.Jitter > 0 {
+		delay += time.Duration(l.rng.Int63n(int64(l.imp.Jitter)))
+	}
+
+	return delay
+}
+
+// schedule delivers d after its delay.
+func (l *impairedLink) schedule(d delivery) {
+	if d.delay <= 0 {
+		l.deliver(d.data)
+		return
+	}
+
+	time.AfterFunc(d.delay, func() {
+		l.deliver(d.data)
+	})
+}
@@ -0,0 +1,90 @@
+package tellosim
+
+import (
+	"testing"
+
+	tello "github.com/hybridgroup/tinygo-tello"
+)
+
+// impairRun sends numbered commands through an impaired transport, with
+// unimpaired stick packets in between, and returns the commands that got
+// through in order.
+func impairRun(t *testing.T, imp Impairment, sticks int) []uint16 {
+	t.Helper()
+
+	local, peer := tello.Pipe()
+	it := ImpairTransport(local, imp)
+	defer it.Close()
+
+	received := make(chan []uint16)
+	go func() {
+		var got []uint16
+		var buf [64]byte
+		for {
+			n, err := peer.Read(buf[:])
+			if err != nil {
+				t.Error(err)
+				received <- got
+				return
+			}
+
+			var pkt tello.Packet
+			if err := pkt.UnmarshalBinary(buf[:n]); err != nil {
+				continue
+			}
+
+			switch pkt.ID {
+			case takeoffCommand.ID:
+				got = append(got, pkt.Seq)
+			case landCommand.ID:
+				received <- got
+				return
+			}
+		}
+	}()
+
+	for seq := uint16(1); seq <= 20; seq++ {
+		for i := 0; i < sticks; i++ {
+			stick, _ := newPacket(stickCommand, make([]byte, 11)).MarshalBinary()
+			it.Write(stick)
+		}
+
+		pkt := newPacket(takeoffCommand, nil)
+		pkt.Seq = seq
+		data, _ := pkt.MarshalBinary()
+		it.Write(data)
+	}
+
+	// The land packet is not impaired, so it marks the end.
+	end, _ := newPacket(landCommand, []byte{0}).MarshalBinary()
+	it.Write(end)
+
+	return <-received
+}
+
+func TestImpairmentFilterIsReproducible(t *testing.T) {
+	imp := Impairment{
+		Loss:      0.3,
+		Duplicate: 0.2,
+		Seed:      7,
+		Filter:    MessageFilter(takeoffCommand.ID),
+	}
+
+	want := impairRun(t, imp, 0)
+	if len(want) == 20 {
+		t.Fatal("no faults were injected")
+	}
+
+	// Stick packets are not impaired, so they must not change the faults.
+	for sticks := 1; sticks <= 3; sticks++ {
+		got := impairRun(t, imp, sticks)
+		if len(got) != len(want) {
+			t.Fatalf("with %d sticks got %v, want %v", sticks, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("with %d sticks got %v, want %v", sticks, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package tellosim
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	tello "github.com/hybridgroup/tinygo-tello"
+)
+
+// Mode is the flight mode of the simulated drone.
+type Mode int
+
+const (
+	// OnGround is when the drone is landed with motors off.
+	OnGround Mode = iota
+
+	// TakingOff is when the drone is climbing to hover height.
+	TakingOff
+
+	// Flying is when the drone is in the air following the sticks.
+	Flying
+
+	// Landing is when the drone is descending to the ground.
+	Landing
+)
+
+// Model limits for the simulated drone.
+const (
+	// MaxSpeed is the horizontal speed at full stick in m/s.
+	MaxSpeed = 5.0
+
+	// MaxClimbRate is the vertical speed at full stick in m/s.
+	MaxClimbRate = 2.0
+
+	// MaxYawRate is the rotation speed at full stick in degrees per second.
+	MaxYawRate = 100.0
+
+	// TakeOffHeight is the height the drone climbs to after takeoff in m.
+	TakeOffHeight = 1.2
+
+	// TakeOffRate is the climb speed during takeoff in m/s.
+	TakeOffRate = 1.0
+
+	// LandingRate is the descent speed during landing in m/s.
+	LandingRate = 0.8
+
+	// responseTime is the time constant for the velocity to follow the
+	// sticks, in seconds.
+	responseTime = 0.3
+
+	// hoverDrain is the battery used per second while in the air, in percent.
+	hoverDrain = 0.12
+
+	// speedDrain is the extra battery used per second at full speed, in percent.
+	speedDrain = 0.05
+
+	// lowBattery and lowerBattery are the warning thresholds in percent.
+	lowBattery   = 20
+	lowerBattery = 10
+)
+
+// Fly modes reported in the flight data.
+const (
+	flyModeGround  = 1
+	flyModeHover   = 6
+	flyModeTakeOff = 11
+	flyModeLanding = 12
+)
+
+// mvoLogRecord is the log record ID for the visual odometry feedback.
+const mvoLogRecord = 0x001d
+
+// State is the simulated state of the drone. Position and velocity are
+// north, east and up, in m and m/s.
+type State struct {
+	Mode Mode
+
+	X, Y, Z    float64
+	VX, VY, VZ float64
+
+	// Yaw is the heading in degrees clockwise from north.
+	Yaw float64
+
+	// YawRate is the rotation speed in degrees per second.
+	YawRate float64
+
+	// Battery is the remaining charge in percent.
+	Battery float64
+
+	// FlyTime is how long the drone has been in the air.
+	FlyTime time.Duration
+}
+
+// newState returns the state of a drone sitting on the ground fully charged.
+func newState() State {
+	return State{Mode: OnGround, Battery: 100}
+}
+
+// step advances the state by dt using the stick positions s.
+func (st *State) step(s Sticks, dt time.Duration) {
+	sec := dt.Seconds()
+
+	var forward, right, up, yaw float64
+	switch st.Mode {
+	case OnGround:
+		st.VX, st.VY, st.VZ, st.YawRate = 0, 0, 0, 0
+		return
+	case TakingOff:
+		up = TakeOffRate / MaxClimbRate
+	case Landing:
+		up = -LandingRate / MaxClimbRate
+	case Flying:
+		forward, right, up, yaw = float64(s.RY), float64(s.RX), float64(s.LY), float64(s.LX)
+	}
+
+	// rotate the body frame stick targets into north and east
+	heading := st.Yaw * math.Pi / 180
+	sin, cos := math.Sin(heading), math.Cos(heading)
+	targetVX := (forward*cos - right*sin) * MaxSpeed
+	targetVY := (forward*sin + right*cos) * MaxSpeed
+	targetVZ := up * MaxClimbRate
+	targetYawRate := yaw * MaxYawRate
+
+	// first order lag towards the targets
+	k := sec / (responseTime + sec)
+	st.VX += (targetVX - st.VX) * k
+	st.VY += (targetVY - st.VY) * k
+	st.VZ += (targetVZ - st.VZ) * k
+	st.YawRate += (targetYawRate - st.YawRate) * k
+
+	st.X += st.VX * sec
+	st.Y += st.VY * sec
+	st.Z += st.VZ * sec
+	st.Yaw = math.Mod(st.Yaw+st.YawRate*sec+360, 360)
+	st.FlyTime += dt
+
+	speed := math.Sqrt(st.VX*st.VX+st.VY*st.VY) / MaxSpeed
+	st.Battery -= (hoverDrain + speedDrain*speed) * sec
+	if st.Battery < 0 {
+		st.Battery = 0
+	}
+
+	switch {
+	case st.Mode == TakingOff && st.Z >= TakeOffHeight:
+		st.Mode = Flying
+	case st.Z <= 0:
+		st.Z = 0
+		st.Mode = OnGround
+		st.VX, st.VY, st.VZ, st.YawRate = 0, 0, 0, 0
+	case st.Battery == 0 && st.Mode == Flying:
+		st.Mode = Landing
+	}
+}
+
+// takeOff starts climbing if the drone is on the ground.
+func (st *State) takeOff() {
+	if st.Mode == OnGround && st.Battery > 0 {
+		st.Mode = TakingOff
+	}
+}
+
+// land starts descending if the drone is in the air.
+func (st *State) land() {
+	if st.Mode == TakingOff || st.Mode == Flying {
+		st.Mode = Landing
+	}
+}
+
+// flightData encodes the state as a flight data payload.
+func (st *State) flightData() []byte {
+	b := make([]byte, 24)
+
+	binary.LittleEndian.PutUint16(b[0:], uint16(int16(st.Z*10)))
+	binary.LittleEndian.PutUint16(b[2:], uint16(int16(st.VX*10)))
+	binary.LittleEndian.PutUint16(b[4:], uint16(int16(st.VY*10)))
+	binary.LittleEndian.PutUint16(b[6:], uint16(int16(st.VZ*10)))
+	binary.LittleEndian.PutUint16(b[8:], uint16(int16(st.FlyTime/(100*time.Millisecond))))
+
+	battery := int(math.Ceil(st.Battery))
+	b[12] = byte(battery)
+
+	var flags, mode byte
+	switch st.Mode {
+	case OnGround:
+		flags |= 0x02
+		mode = flyModeGround
+	case TakingOff:
+		flags |= 0x01
+		mode = flyModeTakeOff
+	case Flying:
+		flags |= 0x01 | 0x08
+		mode = flyModeHover
+	case Landing:
+		flags |= 0x01
+		mode = flyModeLanding
+	}
+	if battery <= lowBattery {
+		flags |= 0x20
+	}
+	if battery <= lowerBattery {
+		flags |= 0x40
+	}
+	b[17] = flags
+	b[18] = mode
+
+	return b
+}
+
+// logData encodes a log data payload holding a single visual odometry
+// record with the velocity in cm/s and the position in m. key is used to
+// scramble the record as the drone does.
+func (st *State) logData(key byte) []byte {
+	var mvo [20]byte
+	binary.LittleEndian.PutUint16(mvo[2:], uint16(int16(st.VX*100)))
+	binary.LittleEndian.PutUint16(mvo[4:], uint16(int16(st.VY*100)))
+	binary.LittleEndian.PutUint16(mvo[6:], uint16(int16(-st.VZ*100)))
+	binary.LittleEndian.PutUint32(mvo[8:], math.Float32bits(float32(st.X)))
+	binary.LittleEndian.PutUint32(mvo[12:], math.Float32bits(float32(st.Y)))
+	binary.LittleEndian.PutUint32(mvo[16:], math.Float32bits(float32(-st.Z)))
+
+	return encodeLogRecord(mvoLogRecord, key, mvo[:])
+}
+
+// encodeLogRecord builds a log data payload with one record. Each record
+// has a 10 byte header, the payload xor'ed with key, and a CRC16.
+func encodeLogRecord(id uint16, key byte, payload []byte) []byte {
+	size := 10 + len(payload) + 2
+
+	b := make([]byte, 1+size)
+	r := b[1:]
+	r[0] = 0x55
+	binary.LittleEndian.PutUint16(r[1:], uint16(size))
+	r[3] = tello.CalculateCRC8(r[0:3])
+	binary.LittleEndian.PutUint16(r[4:], id)
+	r[6] = key
+	for i, v := range payload {
+		r[10+i] = v ^ key
+	}
+	binary.LittleEndian.PutUint16(r[size-2:], tello.CalculateCRC16(r[:size-2]))
+
+	return b
+}
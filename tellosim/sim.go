@@ -0,0 +1,476 @@
+// Package tellosim is a simulated Tello drone for developing and testing
+// without a real drone.
+//
+// The simulator answers the connection request, acknowledges commands with
+// the same ID and sequence number, streams flight data, WiFi and log
+// messages, and records every stick packet it receives.
+//
+// The sticks drive a simple model of the drone's velocity, heading, height
+// and battery, which is reported back in the flight data and in visual
+// odometry log records. The model moves forward by TelemetryInterval for
+// every telemetry update, so with ManualStep the results are the same on
+// every run.
+//
+// Once the client asks for video, the simulator answers with the SPS and
+// PPS and then sends one picture from a canned H.264 stream on every
+// telemetry update, split into datagrams the same way as the drone.
+//
+// ImpairDialer and ImpairTransport inject packet loss, duplication, delay
+// and reordering, to test how a client copes with a poor link.
+package tellosim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	tello "github.com/hybridgroup/tinygo-tello"
+)
+
+// Messages used by the simulator, from the tello message registry.
+var (
+	wifiMessage     = message("wifi")
+	stickCommand    = message("stick")
+	takeoffCommand  = message("takeoff")
+	landCommand     = message("land")
+	flightMessage   = message("flight")
+	throwTakeoff    = message("throw-takeoff")
+	palmLandCommand = message("palm-land")
+	logDataMessage  = message("log-data")
+)
+
+// message returns the registry entry for a message the simulator uses.
+func message(name string) tello.MessageInfo {
+	m, ok := tello.LookupMessageName(name)
+	if !ok {
+		panic("tellosim: message not in registry: " + name)
+	}
+
+	return m
+}
+
+// packetTypeAck is the packet type used for acknowledgements.
+const packetTypeAck = 0x90
+
+// DefaultTelemetryInterval is how often telemetry is sent to the client.
+const DefaultTelemetryInterval = 100 * time.Millisecond
+
+// Config is the configuration for a simulated drone. Any zero field uses
+// its default.
+type Config struct {
+	// TelemetryInterval is how often flight data, WiFi and log messages are
+	// sent. Default is DefaultTelemetryInterval.
+	TelemetryInterval time.Duration
+
+	// MaxSticks is how many stick packets are kept. Older ones are dropped.
+	// Default is to keep them all.
+	MaxSticks int
+
+	// ManualStep stops telemetry from being sent on a timer. Call Step to
+	// advance the model and send telemetry instead.
+	ManualStep bool
+
+	// Video is an H.264 Annex B elementary stream that is sent in a loop.
+	// NAL units can start with 3 or 4 byte start codes. Default is a tiny
+	// grey picture.
+	Video []byte
+
+	// Log is called with a description of every message received.
+	Log func(msg string)
+}
+
+// Sticks is a decoded stick packet. Axes range from -1 to 1.
+type Sticks struct {
+	RX, RY, LX, LY float32
+	Throttle       int
+	Received       time.Time
+}
+
+// Drone is a simulated Tello.
+type Drone struct {
+	cfg Config
+
+	mu        sync.Mutex
+	clients   []tello.Transport
+	video     []tello.Transport
+	sticks    []Sticks
+	current   Sticks
+	state     State
+	ticks     int
+	videoPort uint16
+	stream    *videoStream
+	streaming bool
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// New returns a new simulated drone. It starts sending telemetry to
+// clients as soon as they connect.
+func New(cfg Config) *Drone {
+	if cfg.TelemetryInterval == 0 {
+		cfg.TelemetryInterval = DefaultTelemetryInterval
+	}
+	if cfg.Video == nil {
+		cfg.Video = defaultVideo()
+	}
+
+	d := &Drone{
+		cfg:    cfg,
+		state:  newState(),
+		stream: newVideoStream(cfg.Video),
+		done:   make(chan struct{}),
+	}
+
+	if !cfg.ManualStep {
+		d.wg.Add(1)
+		go d.sendTelemetry()
+	}
+
+	return d
+}
+
+// Dialer returns a tello.Dialer that connects a client to the simulator
+// in memory.
+func (d *Drone) Dialer() tello.Dialer {
+	return &tello.PipeDialer{Accept: d.accept}
+}
+
+// accept is called for each transport the client opens in memory.
+func (d *Drone) accept(laddr, raddr string, peer tello.Transport) {
+	if raddr == "" {
+		d.mu.Lock()
+		d.video = append(d.video, peer)
+		d.mu.Unlock()
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.Serve(peer)
+	}()
+}
+
+// ListenAndServe listens for a client on the UDP address addr, such as
+// ":8889", and serves it until Close is called.
+func (d *Drone) ListenAndServe(addr string) error {
+	local, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenUDP("udp", local)
+	if err != nil {
+		return err
+	}
+
+	return d.Serve(&udpPeer{conn: conn})
+}
+
+// Serve answers the client on conn until it or the simulator is closed.
+func (d *Drone) Serve(conn tello.Transport) error {
+	d.mu.Lock()
+	d.clients = append(d.clients, conn)
+	d.mu.Unlock()
+
+	defer d.removeClient(conn)
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		select {
+		case <-d.done:
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	var buf [2048]byte
+	var pkt tello.Packet
+
+	for {
+		n, err := conn.Read(buf[:])
+		if err != nil {
+			select {
+			case <-d.done:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		d.handle(conn, &pkt, buf[:n])
+	}
+}
+
+// Close stops the simulator and closes all client connections.
+func (d *Drone) Close() error {
+	d.closeOnce.Do(func() {
+		close(d.done)
+
+		d.mu.Lock()
+		for _, v := range d.video {
+			v.Close()
+		}
+		d.video = nil
+		d.mu.Unlock()
+	})
+	d.wg.Wait()
+
+	return nil
+}
+
+// Sticks returns the stick packets received so far, oldest first.
+func (d *Drone) Sticks() []Sticks {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := make([]Sticks, len(d.sticks))
+	copy(s, d.sticks)
+
+	return s
+}
+
+// ClearSticks forgets the stick packets received so far.
+func (d *Drone) ClearSticks() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sticks = d.sticks[:0]
+}
+
+// Flying returns true if the simulated drone is in the air.
+func (d *Drone) Flying() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.state.Mode != OnGround
+}
+
+// State returns the current state of the model.
+func (d *Drone) State() State {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.state
+}
+
+// VideoPort returns the video port requested by the client, or 0 if no
+// client has connected.
+func (d *Drone) VideoPort() uint16 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.videoPort
+}
+
+func (d *Drone) removeClient(conn tello.Transport) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, c := range d.clients {
+		if c == conn {
+			d.clients = append(d.clients[:i], d.clients[i+1:]...)
+			return
+		}
+	}
+}
+
+// handle answers a single datagram from the client.
+func (d *Drone) handle(conn tello.Transport, pkt *tello.Packet, data []byte) {
+	if bytes.HasPrefix(data, []byte("conn_req:")) {
+		d.log("conn_req")
+		if len(data) >= 11 {
+			d.mu.Lock()
+			d.videoPort = binary.LittleEndian.Uint16(data[9:])
+			d.mu.Unlock()
+		}
+
+		reply := []byte("conn_ack:xx")
+		copy(reply[9:], data[9:])
+		conn.Write(reply)
+		return
+	}
+
+	if err := pkt.UnmarshalBinary(data); err != nil {
+		d.log("invalid packet: " + err.Error())
+		return
+	}
+
+	if pkt.ID != stickCommand.ID {
+		d.log(pkt.String())
+	}
+
+	switch pkt.ID {
+	case stickCommand.ID:
+		d.recordSticks(pkt.Payload)
+	case takeoffCommand.ID, throwTakeoff.ID:
+		d.mu.Lock()
+		d.state.takeOff()
+		d.mu.Unlock()
+	case landCommand.ID, palmLandCommand.ID:
+		d.mu.Lock()
+		d.state.land()
+		d.mu.Unlock()
+	case videoStartCommand.ID:
+		d.mu.Lock()
+		d.streaming = true
+		start := d.stream.start()
+		d.mu.Unlock()
+
+		d.sendVideo(start)
+	}
+
+	if m, ok := tello.LookupMessage(pkt.ID); ok && m.Ack {
+		d.send(conn, &tello.Packet{Type: packetTypeAck, ID: pkt.ID, Seq: pkt.Seq, Payload: []byte{0x00}})
+	}
+}
+
+// recordSticks decodes and records a stick packet.
+func (d *Drone) recordSticks(payload []byte) {
+	if len(payload) < 6 {
+		return
+	}
+
+	var b [8]byte
+	copy(b[:], payload[:6])
+	packed := binary.LittleEndian.Uint64(b[:])
+
+	axis := func(shift uint) float32 {
+		return (float32((packed>>shift)&0x7ff) - 1024) / 660
+	}
+
+	s := Sticks{
+		RX:       axis(0),
+		RY:       axis(11),
+		LY:       axis(22),
+		LX:       axis(33),
+		Throttle: int((packed >> 44) & 0x0f),
+		Received: time.Now(),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.current = s
+	d.sticks = append(d.sticks, s)
+	if d.cfg.MaxSticks > 0 && len(d.sticks) > d.cfg.MaxSticks {
+		d.sticks = append(d.sticks[:0], d.sticks[len(d.sticks)-d.cfg.MaxSticks:]...)
+	}
+}
+
+// sendTelemetry calls Step every TelemetryInterval until the simulator is
+// closed.
+func (d *Drone) sendTelemetry() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.TelemetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+		}
+
+		d.Step()
+	}
+}
+
+// Step advances the model by TelemetryInterval using the latest sticks, then
+// sends flight data, WiFi and log messages to every client, and the next
+// picture if video has been started.
+func (d *Drone) Step() {
+	d.mu.Lock()
+	d.state.step(d.current, d.cfg.TelemetryInterval)
+	d.ticks++
+	clients := append([]tello.Transport(nil), d.clients...)
+	flight := d.state.flightData()
+	logData := d.state.logData(byte(d.ticks))
+
+	var picture []byte
+	if d.streaming {
+		picture = d.stream.picture()
+	}
+	d.mu.Unlock()
+
+	for _, c := range clients {
+		d.send(c, newPacket(flightMessage, flight))
+		d.send(c, newPacket(wifiMessage, []byte{90, 0}))
+		d.send(c, newPacket(logDataMessage, logData))
+	}
+
+	d.sendVideo(picture)
+}
+
+func (d *Drone) send(conn tello.Transport, pkt *tello.Packet) {
+	data, err := pkt.MarshalBinary()
+	if err != nil {
+		d.log("encode error: " + err.Error())
+		return
+	}
+
+	conn.Write(data)
+}
+
+func (d *Drone) log(msg string) {
+	if d.cfg.Log != nil {
+		d.cfg.Log(msg)
+	}
+}
+
+// newPacket builds a packet for a message sent by the drone.
+func newPacket(m tello.MessageInfo, payload []byte) *tello.Packet {
+	return &tello.Packet{Type: m.Type, ID: m.ID, Payload: payload}
+}
+
+// udpPeer is a tello.Transport for a UDP socket that answers whoever last
+// sent to it.
+type udpPeer struct {
+	conn *net.UDPConn
+
+	mu    sync.Mutex
+	peer  *net.UDPAddr
+	video *net.UDPConn
+}
+
+func (u *udpPeer) Read(b []byte) (int, error) {
+	n, addr, err := u.conn.ReadFromUDP(b)
+	if err != nil {
+		return n, err
+	}
+
+	u.mu.Lock()
+	u.peer = addr
+	u.mu.Unlock()
+
+	return n, nil
+}
+
+func (u *udpPeer) Write(b []byte) (int, error) {
+	u.mu.Lock()
+	peer := u.peer
+	u.mu.Unlock()
+
+	if peer == nil {
+		return len(b), nil
+	}
+
+	return u.conn.WriteToUDP(b, peer)
+}
+
+func (u *udpPeer) Close() error {
+	u.mu.Lock()
+	if u.video != nil {
+		u.video.Close()
+		u.video = nil
+	}
+	u.mu.Unlock()
+
+	return u.conn.Close()
+}
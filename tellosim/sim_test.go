@@ -0,0 +1,142 @@
+package tellosim_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tello "github.com/hybridgroup/tinygo-tello"
+	"github.com/hybridgroup/tinygo-tello/tellosim"
+)
+
+func TestTakeOffAndLand(t *testing.T) {
+	sim := tellosim.New(tellosim.Config{})
+	defer sim.Close()
+
+	drone := tello.NewWithConfig(tello.Config{Dialer: sim.Dialer()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := drone.StartContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer drone.Stop()
+
+	if !drone.Connected() {
+		t.Error("not connected after StartContext")
+	}
+
+	frames := drone.Subscribe(1, tello.VideoFrameEvent)
+	if err := drone.StartVideo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := drone.TakeOffAndWait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !sim.Flying() {
+		t.Error("simulator is not flying after takeoff")
+	}
+
+	if err := drone.LandAndWait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if sim.Flying() {
+		t.Error("simulator is flying after landing")
+	}
+	if s := drone.FlightState(); s != tello.StateOnGround {
+		t.Errorf("flight state is %v after landing", s)
+	}
+
+	select {
+	case <-frames:
+	case <-ctx.Done():
+		t.Error("no video frame received")
+	}
+
+	drone.Stop()
+	if drone.Connected() {
+		t.Error("still connected after Stop")
+	}
+}
+
+func TestRetriesRecoverFromLoss(t *testing.T) {
+	var mu sync.Mutex
+	received := 0
+
+	sim := tellosim.New(tellosim.Config{
+		Log: func(msg string) {
+			if strings.HasPrefix(msg, "eis ") {
+				mu.Lock()
+				received++
+				mu.Unlock()
+			}
+		},
+	})
+	defer sim.Close()
+
+	// Only the EIS commands and their acknowledgements are impaired, so
+	// the sticks and telemetry do not change which ones are lost.
+	eis, _ := tello.LookupMessageName("eis")
+	imp := tellosim.Impairment{Loss: 0.2, Seed: 1, Filter: tellosim.MessageFilter(eis.ID)}
+
+	drone := tello.NewWithConfig(tello.Config{
+		Dialer:     tellosim.ImpairDialer(sim.Dialer(), imp),
+		AckTimeout: 50 * time.Millisecond,
+		Retries:    6,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := drone.StartContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer drone.Stop()
+
+	const commands = 20
+	for i := 0; i < commands; i++ {
+		on := i%2 == 0
+		if err := drone.SetEIS(on); err != nil {
+			t.Fatalf("command %d: %v", i, err)
+		}
+		if drone.EIS() != on {
+			t.Fatalf("command %d: EIS not set", i)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if received <= commands {
+		t.Errorf("simulator received %d commands, want retries on top of %d", received, commands)
+	}
+}
+
+func TestLandRightAfterTakeOff(t *testing.T) {
+	sim := tellosim.New(tellosim.Config{})
+	defer sim.Close()
+
+	drone := tello.NewWithConfig(tello.Config{Dialer: sim.Dialer()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := drone.StartContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer drone.Stop()
+
+	// Flight data still says the drone is on the ground, which must not
+	// stop it from being told to land.
+	takeoff := drone.TakeOffAsync()
+	if err := drone.Land(); err != nil {
+		t.Fatal(err)
+	}
+	if err := takeoff.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,181 @@
+package tellosim
+
+import (
+	"bytes"
+	"net"
+
+	tello "github.com/hybridgroup/tinygo-tello"
+)
+
+// videoStartCommand asks the drone to send the SPS and PPS.
+var videoStartCommand = message("video-start")
+
+// videoFragmentLen is the largest video datagram the drone sends,
+// including the 2 byte header.
+const videoFragmentLen = 1460
+
+// videoStream is a canned H.264 stream split into NAL units.
+type videoStream struct {
+	sps, pps []byte
+	pictures [][]byte
+	next     int
+	frame    byte
+}
+
+// newVideoStream splits an Annex B elementary stream into NAL units.
+func newVideoStream(stream []byte) *videoStream {
+	v := &videoStream{}
+
+	for _, unit := range splitNALUnits(stream) {
+		// The NAL header follows the 1 that ends the start code.
+		header := bytes.IndexByte(unit, 1) + 1
+		if header >= len(unit) {
+			continue
+		}
+
+		switch unit[header] & 0x1f {
+		case 7:
+			v.sps = unit
+		case 8:
+			v.pps = unit
+		default:
+			v.pictures = append(v.pictures, unit)
+		}
+	}
+
+	return v
+}
+
+// splitNALUnits splits an Annex B stream at each 3 or 4 byte start code.
+// Each unit keeps its start code.
+func splitNALUnits(stream []byte) [][]byte {
+	var starts []int
+	for i := 0; i+3 <= len(stream); i++ {
+		if stream[i] != 0 || stream[i+1] != 0 || stream[i+2] != 1 {
+			continue
+		}
+
+		start := i
+		if start > 0 && stream[start-1] == 0 {
+			start--
+		}
+		starts = append(starts, start)
+		i += 2
+	}
+
+	var units [][]byte
+	for i, start := range starts {
+		end := len(stream)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		units = append(units, stream[start:end])
+	}
+
+	return units
+}
+
+// start returns the SPS and PPS, and rewinds the stream to its first picture.
+func (v *videoStream) start() []byte {
+	v.next = 0
+	return append(append([]byte(nil), v.sps...), v.pps...)
+}
+
+// picture returns the next picture, looping at the end of the stream.
+func (v *videoStream) picture() []byte {
+	if len(v.pictures) == 0 {
+		return nil
+	}
+
+	p := v.pictures[v.next]
+	v.next = (v.next + 1) % len(v.pictures)
+
+	return p
+}
+
+// fragments splits data into video datagrams. Each one starts with the
+// frame number and the fragment index, with the top bit of the index set
+// on the last fragment.
+func (v *videoStream) fragments(data []byte) [][]byte {
+	var out [][]byte
+
+	for i := 0; len(data) > 0 || i == 0; i++ {
+		n := len(data)
+		if n > videoFragmentLen-2 {
+			n = videoFragmentLen - 2
+		}
+
+		index := byte(i)
+		if n == len(data) {
+			index |= 0x80
+		}
+
+		out = append(out, append([]byte{v.frame, index}, data[:n]...))
+		data = data[n:]
+	}
+	v.frame++
+
+	return out
+}
+
+// sendVideo fragments data and sends it to every video transport.
+func (d *Drone) sendVideo(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	fragments := d.stream.fragments(data)
+	targets := d.videoTransports()
+	d.mu.Unlock()
+
+	for _, t := range targets {
+		for _, f := range fragments {
+			t.Write(f)
+		}
+	}
+}
+
+// videoTransports returns where to send video. The caller must hold mu.
+func (d *Drone) videoTransports() []tello.Transport {
+	targets := append([]tello.Transport(nil), d.video...)
+
+	for _, c := range d.clients {
+		if u, ok := c.(*udpPeer); ok {
+			if v := u.videoConn(d.videoPort); v != nil {
+				targets = append(targets, v)
+			}
+		}
+	}
+
+	return targets
+}
+
+// videoConn returns a UDP connection to the video port of whoever last sent
+// to the peer, or nil if no one has.
+func (u *udpPeer) videoConn(port uint16) tello.Transport {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.peer == nil || port == 0 {
+		return nil
+	}
+
+	addr := &net.UDPAddr{IP: u.peer.IP, Port: int(port), Zone: u.peer.Zone}
+	if u.video != nil && u.video.RemoteAddr().String() == addr.String() {
+		return u.video
+	}
+
+	if u.video != nil {
+		u.video.Close()
+		u.video = nil
+	}
+
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil
+	}
+
+	u.video = conn
+	return conn
+}
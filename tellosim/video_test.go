@@ -0,0 +1,52 @@
+package tellosim
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitNALUnits(t *testing.T) {
+	stream := []byte{
+		0, 0, 0, 1, 0x67, 0xaa,
+		0, 0, 1, 0x68, 0xbb,
+		0, 0, 1, 0x65, 0xcc, 0xdd,
+		0, 0, 0, 1,
+	}
+
+	want := [][]byte{
+		{0, 0, 0, 1, 0x67, 0xaa},
+		{0, 0, 1, 0x68, 0xbb},
+		{0, 0, 1, 0x65, 0xcc, 0xdd},
+		{0, 0, 0, 1},
+	}
+
+	units := splitNALUnits(stream)
+	if len(units) != len(want) {
+		t.Fatalf("got %d units, want %d", len(units), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(units[i], want[i]) {
+			t.Errorf("unit %d is %x, want %x", i, units[i], want[i])
+		}
+	}
+}
+
+func TestNewVideoStream(t *testing.T) {
+	stream := []byte{
+		0, 0, 0, 1, 0x67, 0xaa,
+		0, 0, 1, 0x68, 0xbb,
+		0, 0, 1, 0x65, 0xcc,
+		0, 0, 0, 1,
+	}
+
+	v := newVideoStream(stream)
+	if !bytes.Equal(v.sps, []byte{0, 0, 0, 1, 0x67, 0xaa}) {
+		t.Errorf("sps is %x", v.sps)
+	}
+	if !bytes.Equal(v.pps, []byte{0, 0, 1, 0x68, 0xbb}) {
+		t.Errorf("pps is %x", v.pps)
+	}
+	if len(v.pictures) != 1 {
+		t.Errorf("got %d pictures, want 1", len(v.pictures))
+	}
+}
@@ -0,0 +1,152 @@
+package tello
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+// ErrClosed is returned when using a transport that has been closed.
+var ErrClosed = errors.New("tello: transport closed")
+
+// Transport sends and receives datagrams. Each Read returns a single
+// datagram and each Write sends one.
+type Transport interface {
+	Read(b []byte) (int, error)
+	Write(b []byte) (int, error)
+	Close() error
+}
+
+// Dialer opens the transports used to talk to the drone.
+type Dialer interface {
+	// Dial opens a transport from the local address laddr to the remote
+	// address raddr. It is used for commands and their responses.
+	Dial(laddr, raddr string) (Transport, error)
+
+	// Listen opens a transport on the local address laddr that receives
+	// from anyone. It is used for video.
+	Listen(laddr string) (Transport, error)
+}
+
+// UDPDialer is a Dialer that uses UDP from the net package.
+type UDPDialer struct{}
+
+// Dial opens a UDP connection from laddr to raddr.
+func (UDPDialer) Dial(laddr, raddr string) (Transport, error) {
+	remote, err := net.ResolveUDPAddr("udp", raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := net.ResolveUDPAddr("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialUDP("udp", local, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Listen opens a UDP socket on laddr.
+func (UDPDialer) Listen(laddr string) (Transport, error) {
+	local, err := net.ResolveUDPAddr("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp", local)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// pipeQueueLen is how many datagrams a pipe holds before writes block.
+const pipeQueueLen = 32
+
+// pipeEnd is one end of an in-memory datagram pipe.
+type pipeEnd struct {
+	rx   chan []byte
+	tx   chan []byte
+	done chan struct{}
+	once *sync.Once
+}
+
+// Pipe returns both ends of an in-memory datagram transport. Whatever is
+// written to one end can be read from the other. Closing either end closes
+// both.
+func Pipe() (Transport, Transport) {
+	ab := make(chan []byte, pipeQueueLen)
+	ba := make(chan []byte, pipeQueueLen)
+	done := make(chan struct{})
+	once := &sync.Once{}
+
+	return &pipeEnd{rx: ba, tx: ab, done: done, once: once},
+		&pipeEnd{rx: ab, tx: ba, done: done, once: once}
+}
+
+// Read returns the next datagram, truncated to fit in b.
+func (p *pipeEnd) Read(b []byte) (int, error) {
+	select {
+	case d := <-p.rx:
+		return copy(b, d), nil
+	case <-p.done:
+		return 0, ErrClosed
+	}
+}
+
+// Write sends b as one datagram.
+func (p *pipeEnd) Write(b []byte) (int, error) {
+	d := make([]byte, len(b))
+	copy(d, b)
+
+	select {
+	case <-p.done:
+		return 0, ErrClosed
+	default:
+	}
+
+	select {
+	case p.tx <- d:
+		return len(b), nil
+	case <-p.done:
+		return 0, ErrClosed
+	}
+}
+
+// Close closes both ends of the pipe.
+func (p *pipeEnd) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+	})
+
+	return nil
+}
+
+// PipeDialer is a Dialer that opens in-memory transports, so that the
+// client can be run without a network.
+type PipeDialer struct {
+	// Accept is called with the far end of every transport that is opened.
+	// For transports opened by Listen raddr is empty.
+	Accept func(laddr, raddr string, peer Transport)
+}
+
+// Dial opens an in-memory transport and passes the far end to Accept.
+func (d *PipeDialer) Dial(laddr, raddr string) (Transport, error) {
+	local, peer := Pipe()
+	if d.Accept != nil {
+		d.Accept(laddr, raddr, peer)
+	}
+
+	return local, nil
+}
+
+// Listen opens an in-memory transport and passes the far end to Accept.
+func (d *PipeDialer) Listen(laddr string) (Transport, error) {
+	return d.Dial(laddr, "")
+}
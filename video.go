@@ -0,0 +1,46 @@
+package tello
+
+// videoAssembler joins the datagrams the drone sends on the video port back
+// into H.264 data. Each datagram starts with the frame number and the index
+// of the fragment within the frame, with the top bit of the index set on
+// the last fragment. A frame with a missing or out of order fragment is
+// dropped.
+type videoAssembler struct {
+	buf    []byte
+	frame  byte
+	next   byte
+	broken bool
+}
+
+// add adds a datagram and returns the frame once its last fragment arrives.
+// The frame is only valid until the next call to add.
+func (v *videoAssembler) add(data []byte) ([]byte, bool) {
+	if len(data) < 2 {
+		return nil, false
+	}
+
+	frame, index, last := data[0], data[1]&0x7f, data[1]&0x80 != 0
+
+	if index == 0 {
+		v.buf = v.buf[:0]
+		v.frame = frame
+		v.broken = false
+	} else if frame != v.frame || index != v.next {
+		v.broken = true
+	}
+
+	v.next = index + 1
+	if !v.broken {
+		v.buf = append(v.buf, data[2:]...)
+	}
+
+	if !last {
+		return nil, false
+	}
+
+	// wait for the first fragment of the next frame
+	ok := !v.broken
+	v.broken = true
+
+	return v.buf, ok
+}
@@ -0,0 +1,55 @@
+package tello
+
+import (
+	"context"
+	"time"
+)
+
+// statePollInterval is how often the flight state is checked while waiting,
+// in case a FlightStateEvent was dropped.
+const statePollInterval = 100 * time.Millisecond
+
+// TakeOffAndWait tells the Tello to takeoff, then waits until its flight
+// data reports it is flying. If ctx is done first, it returns an error
+// matching both ErrTimeout and ctx.Err().
+func (t *Tello) TakeOffAndWait(ctx context.Context) error {
+	events := t.Subscribe(4, FlightStateEvent)
+	defer t.Unsubscribe(events)
+
+	if err := t.TakeOff(); err != nil {
+		return err
+	}
+
+	return t.waitForState(ctx, events, StateFlying)
+}
+
+// LandAndWait tells the Tello to land, then waits until its flight data
+// reports it is on the ground. If ctx is done first, it returns an error
+// matching both ErrTimeout and ctx.Err().
+func (t *Tello) LandAndWait(ctx context.Context) error {
+	events := t.Subscribe(4, FlightStateEvent)
+	defer t.Unsubscribe(events)
+
+	if err := t.Land(); err != nil {
+		return err
+	}
+
+	return t.waitForState(ctx, events, StateOnGround)
+}
+
+// waitForState waits until the flight state is want.
+func (t *Tello) waitForState(ctx context.Context, events <-chan Event, want FlightState) error {
+	ticker := time.NewTicker(statePollInterval)
+	defer ticker.Stop()
+
+	for t.FlightState() != want {
+		select {
+		case <-ctx.Done():
+			return &contextError{err: ErrTimeout, cause: ctx.Err()}
+		case <-events:
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
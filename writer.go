@@ -0,0 +1,228 @@
+package tello
+
+import (
+	"sync"
+	"time"
+)
+
+// sendQueueLen is how many commands of each priority wait to be sent
+// before ErrQueueFull is returned.
+const sendQueueLen = 16
+
+// priority orders the commands waiting to be sent to the drone.
+type priority byte
+
+const (
+	// priorityUrgent is for commands that bring the drone down, and is
+	// sent before anything else.
+	priorityUrgent priority = iota
+
+	// priorityNormal is for flight commands and the connection request.
+	priorityNormal
+
+	// priorityLow is for flips and settings.
+	priorityLow
+
+	priorityCount
+)
+
+// commandPriority returns the priority used to send a message.
+func commandPriority(id uint16) priority {
+	switch id {
+	case landCommand, palmLandCommand:
+		return priorityUrgent
+	case takeoffCommand, throwtakeoffCommand, videoStartCommand:
+		return priorityNormal
+	}
+
+	return priorityLow
+}
+
+// outgoing is a datagram waiting to be sent. Either pkt is encoded, or raw
+// is sent as it is.
+type outgoing struct {
+	pkt *Packet
+	raw []byte
+
+	// dropped is how many stick packets this one replaced before they
+	// could be sent.
+	dropped int
+}
+
+// sendQueue holds what is waiting to be sent by the writer goroutine, so
+// that callers never wait for the network.
+type sendQueue struct {
+	mu     sync.Mutex
+	open   bool
+	queues [priorityCount][]outgoing
+
+	// sticks is the latest stick packet. A newer one replaces it if it has
+	// not been sent yet.
+	sticks outgoing
+
+	// ready is signalled when something is added.
+	ready chan struct{}
+}
+
+// reset empties the queue and lets commands be added.
+func (q *sendQueue) reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.open = true
+	q.clear()
+	if q.ready == nil {
+		q.ready = make(chan struct{}, 1)
+	}
+}
+
+// close empties the queue and stops commands being added.
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.open = false
+	q.clear()
+}
+
+// clear drops everything waiting. The caller must hold mu.
+func (q *sendQueue) clear() {
+	for i := range q.queues {
+		q.queues[i] = q.queues[i][:0]
+	}
+	q.sticks = outgoing{}
+}
+
+// push adds a datagram to be sent after those of the same or higher
+// priority that are already waiting.
+func (q *sendQueue) push(p priority, o outgoing) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.open {
+		return ErrNotConnected
+	}
+	if len(q.queues[p]) >= sendQueueLen {
+		return ErrQueueFull
+	}
+
+	q.queues[p] = append(q.queues[p], o)
+	q.signal()
+
+	return nil
+}
+
+// setSticks replaces the stick packet waiting to be sent.
+func (q *sendQueue) setSticks(pkt *Packet) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.open {
+		return ErrNotConnected
+	}
+
+	dropped := 0
+	if q.sticks.pkt != nil {
+		dropped = q.sticks.dropped + 1
+	}
+
+	q.sticks = outgoing{pkt: pkt, dropped: dropped}
+	q.signal()
+
+	return nil
+}
+
+// signal wakes the writer. The caller must hold mu.
+func (q *sendQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop returns the next datagram to send. Urgent commands go first, then
+// the sticks, then everything else by priority.
+func (q *sendQueue) pop() (outgoing, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if o, ok := q.take(priorityUrgent); ok {
+		return o, true
+	}
+
+	if q.sticks.pkt != nil {
+		o := q.sticks
+		q.sticks = outgoing{}
+		return o, true
+	}
+
+	for p := priorityNormal; p < priorityCount; p++ {
+		if o, ok := q.take(p); ok {
+			return o, true
+		}
+	}
+
+	return outgoing{}, false
+}
+
+// take removes the first datagram of a priority. The caller must hold mu.
+func (q *sendQueue) take(p priority) (outgoing, bool) {
+	if len(q.queues[p]) == 0 {
+		return outgoing{}, false
+	}
+
+	o := q.queues[p][0]
+	copy(q.queues[p], q.queues[p][1:])
+	q.queues[p][len(q.queues[p])-1] = outgoing{}
+	q.queues[p] = q.queues[p][:len(q.queues[p])-1]
+
+	return o, true
+}
+
+// writer sends everything queued to the drone until done is closed. It is
+// the only goroutine that writes to conn once the client is started.
+func (t *Tello) writer(conn Transport, done chan struct{}) {
+	defer t.wg.Done()
+
+	var buf [64]byte
+
+	for {
+		for {
+			o, ok := t.queue.pop()
+			if !ok {
+				break
+			}
+
+			data := o.raw
+			if o.pkt != nil {
+				n, err := o.pkt.encode(buf[:])
+				if err != nil {
+					t.reportError("send", err)
+					continue
+				}
+				data = buf[:n]
+			}
+
+			if _, err := conn.Write(data); err != nil {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				t.reportError("send", err)
+				continue
+			}
+
+			if o.pkt != nil && o.pkt.ID == stickCommand {
+				t.recordStickSent(time.Now(), o.dropped)
+			}
+		}
+
+		select {
+		case <-done:
+			return
+		case <-t.queue.ready:
+		}
+	}
+}